@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tiamxu/kit/redis"
+)
+
+const (
+	// TypeRedis Redis 缓存
+	TypeRedis = "redis"
+	// TypeMemory 进程内 LRU 缓存
+	TypeMemory = "memory"
+	// TypeTiered 进程内 LRU + Redis 两级缓存
+	TypeTiered = "tiered"
+	// TypeNoop 不缓存，用于关闭缓存或测试
+	TypeNoop = "noop"
+)
+
+// NoExpiration TTL 返回该值表示 key 未设置过期时间
+const NoExpiration time.Duration = -1
+
+var (
+	// ErrCacheMiss key 不在缓存中，所有缓存实现的 Get 在未命中时均返回该错误
+	ErrCacheMiss = errors.New("cache: miss")
+	// ErrNotFound 数据确定不存在: 缓存中记录了不存在标记，或 Loader 返回该错误表示数据源中没有该数据
+	ErrNotFound = errors.New("cache: not found")
+)
+
+// Cache 泛型缓存接口，ttl 为0表示不过期
+type Cache[T any] interface {
+	// Get 读取缓存，未命中时返回 ErrCacheMiss；key 记录为不存在时返回 ErrNotFound
+	Get(ctx context.Context, key string) (T, error)
+	Set(ctx context.Context, key string, value T, ttl time.Duration) error
+	// SetAbsent 记录 key 对应的数据不存在(负缓存)，ttl 通常远小于正常数据的过期时间
+	SetAbsent(ctx context.Context, key string, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	// GetMulti 批量读取，返回结果仅包含命中的 key，记录为不存在的 key 同样不在结果中
+	GetMulti(ctx context.Context, keys []string) (map[string]T, error)
+	SetMulti(ctx context.Context, items map[string]T, ttl time.Duration) error
+	// TTL 返回剩余过期时间，key 不存在时返回0，未设置过期时间时返回 NoExpiration
+	TTL(ctx context.Context, key string) (time.Duration, error)
+}
+
+// Config 缓存配置
+type Config struct {
+	// 缓存类型: redis(默认)、memory、tiered、noop
+	Type string `yaml:"type"`
+	// memory 类型及 tiered 类型本地缓存的最大条目数，默认10000
+	Size int `yaml:"size"`
+	// tiered 类型本地副本的最长保留时间，默认1m
+	LocalTTL time.Duration `yaml:"local_ttl"`
+	// tiered 类型的失效通知频道，默认为 Redis KeyPrefix + cache:invalidate，多个服务共用 Redis 时建议区分
+	Channel string `yaml:"channel"`
+}
+
+// New 按配置创建缓存，redis 类型需传入 client
+func New[T any](cfg Config, client *redis.RedisClient) (Cache[T], error) {
+	switch cfg.Type {
+	case TypeRedis, "":
+		if client == nil {
+			return nil, fmt.Errorf("redis cache requires redis client")
+		}
+		return NewTypedRedis[T](client), nil
+	case TypeMemory:
+		return NewLRU[T](cfg.Size), nil
+	case TypeTiered:
+		if client == nil {
+			return nil, fmt.Errorf("tiered cache requires redis client")
+		}
+		return NewTiered[T](client, cfg), nil
+	case TypeNoop:
+		return NewNoop[T](), nil
+	default:
+		return nil, fmt.Errorf("unsupported cache type: %s", cfg.Type)
+	}
+}
+
+var (
+	_ Cache[struct{}] = (*TypedRedis[struct{}])(nil)
+	_ Cache[struct{}] = (*LRU[struct{}])(nil)
+	_ Cache[struct{}] = (*Tiered[struct{}])(nil)
+	_ Cache[struct{}] = Noop[struct{}]{}
+)
@@ -0,0 +1,221 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/tiamxu/kit/redis"
+)
+
+func newTestClient(t *testing.T, cfg *redis.Config) (*redis.RedisClient, *miniredis.Miniredis) {
+	t.Helper()
+	s := miniredis.RunT(t)
+	if cfg == nil {
+		cfg = &redis.Config{}
+	}
+	cfg.Address = s.Addr()
+	c, err := redis.NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c, s
+}
+
+type cacheModel struct {
+	ID   int64  `json:"id" msgpack:"id"`
+	Name string `json:"name" msgpack:"name"`
+}
+
+// newBackends 返回待测试的各缓存实现
+func newBackends(t *testing.T) map[string]Cache[cacheModel] {
+	t.Helper()
+	client, _ := newTestClient(t, nil)
+	prefixed, _ := newTestClient(t, &redis.Config{KeyPrefix: "app:", Codec: "msgpack"})
+	tiered := NewTiered[cacheModel](client, Config{Type: TypeTiered})
+	t.Cleanup(func() { _ = tiered.Close() })
+	return map[string]Cache[cacheModel]{
+		"memory":       NewLRU[cacheModel](0),
+		"redis":        NewTypedRedis[cacheModel](client),
+		"redis-prefix": NewTypedRedis[cacheModel](prefixed),
+		"tiered":       tiered,
+	}
+}
+
+// Cache 接口约定: 未命中返回 ErrCacheMiss，不存在标记返回 ErrNotFound
+func TestCacheContract(t *testing.T) {
+	ctx := context.Background()
+	a, b := cacheModel{ID: 1, Name: "a"}, cacheModel{ID: 2, Name: "b"}
+	tests := []struct {
+		name string
+		run  func(t *testing.T, c Cache[cacheModel])
+	}{
+		{"get miss", func(t *testing.T, c Cache[cacheModel]) {
+			if _, err := c.Get(ctx, "missing"); !errors.Is(err, ErrCacheMiss) {
+				t.Fatalf("Get error = %v, want ErrCacheMiss", err)
+			}
+		}},
+		{"set get", func(t *testing.T, c Cache[cacheModel]) {
+			if err := c.Set(ctx, "k", a, time.Minute); err != nil {
+				t.Fatalf("Set: %v", err)
+			}
+			if v, err := c.Get(ctx, "k"); err != nil || v != a {
+				t.Fatalf("Get = %+v, %v, want %+v", v, err, a)
+			}
+		}},
+		{"set absent", func(t *testing.T, c Cache[cacheModel]) {
+			if err := c.SetAbsent(ctx, "absent", time.Minute); err != nil {
+				t.Fatalf("SetAbsent: %v", err)
+			}
+			if _, err := c.Get(ctx, "absent"); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Get error = %v, want ErrNotFound", err)
+			}
+		}},
+		{"delete", func(t *testing.T, c Cache[cacheModel]) {
+			_ = c.Set(ctx, "d1", a, time.Minute)
+			_ = c.SetAbsent(ctx, "d2", time.Minute)
+			if err := c.Delete(ctx, "d1", "d2", "d3"); err != nil {
+				t.Fatalf("Delete: %v", err)
+			}
+			for _, key := range []string{"d1", "d2"} {
+				if _, err := c.Get(ctx, key); !errors.Is(err, ErrCacheMiss) {
+					t.Fatalf("Get %s after Delete error = %v, want ErrCacheMiss", key, err)
+				}
+			}
+		}},
+		{"multi", func(t *testing.T, c Cache[cacheModel]) {
+			if err := c.SetMulti(ctx, map[string]cacheModel{"m1": a, "m2": b}, time.Minute); err != nil {
+				t.Fatalf("SetMulti: %v", err)
+			}
+			_ = c.SetAbsent(ctx, "m3", time.Minute)
+			got, err := c.GetMulti(ctx, []string{"m1", "m2", "m3", "m4"})
+			if err != nil {
+				t.Fatalf("GetMulti: %v", err)
+			}
+			if len(got) != 2 || got["m1"] != a || got["m2"] != b {
+				t.Fatalf("GetMulti = %+v, want only m1 and m2", got)
+			}
+		}},
+		{"ttl", func(t *testing.T, c Cache[cacheModel]) {
+			_ = c.Set(ctx, "t1", a, time.Minute)
+			_ = c.Set(ctx, "t2", a, 0)
+			if ttl, err := c.TTL(ctx, "t1"); err != nil || ttl <= 0 || ttl > time.Minute {
+				t.Fatalf("TTL t1 = %s, %v, want (0, 1m]", ttl, err)
+			}
+			if ttl, err := c.TTL(ctx, "t2"); err != nil || ttl != NoExpiration {
+				t.Fatalf("TTL t2 = %s, %v, want NoExpiration", ttl, err)
+			}
+			if ttl, err := c.TTL(ctx, "t3"); err != nil || ttl != 0 {
+				t.Fatalf("TTL t3 = %s, %v, want 0", ttl, err)
+			}
+		}},
+	}
+	for name, c := range newBackends(t) {
+		for _, tt := range tests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) { tt.run(t, c) })
+		}
+	}
+}
+
+func TestNoop(t *testing.T) {
+	ctx := context.Background()
+	c := NewNoop[cacheModel]()
+	if err := c.Set(ctx, "k", cacheModel{ID: 1}, time.Minute); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if _, err := c.Get(ctx, "k"); !errors.Is(err, ErrCacheMiss) {
+		t.Fatalf("Get error = %v, want ErrCacheMiss", err)
+	}
+	if got, err := c.GetMulti(ctx, []string{"k"}); err != nil || len(got) != 0 {
+		t.Fatalf("GetMulti = %v, %v, want empty", got, err)
+	}
+}
+
+func TestLRUEviction(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU[int](2)
+	_ = c.Set(ctx, "a", 1, 0)
+	_ = c.Set(ctx, "b", 2, 0)
+	// 访问 a 后 b 成为最久未访问的条目
+	if _, err := c.Get(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	_ = c.Set(ctx, "c", 3, 0)
+	if _, err := c.Get(ctx, "b"); !errors.Is(err, ErrCacheMiss) {
+		t.Fatalf("Get b error = %v, want evicted", err)
+	}
+	if c.Len() != 2 {
+		t.Fatalf("Len = %d, want 2", c.Len())
+	}
+
+	_ = c.Set(ctx, "short", 4, 20*time.Millisecond)
+	time.Sleep(30 * time.Millisecond)
+	if _, err := c.Get(ctx, "short"); !errors.Is(err, ErrCacheMiss) {
+		t.Fatalf("Get expired error = %v, want ErrCacheMiss", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	client, _ := newTestClient(t, nil)
+	tests := []struct {
+		cfg     Config
+		client  *redis.RedisClient
+		want    string
+		wantErr bool
+	}{
+		{Config{}, client, "*cache.TypedRedis[github.com/tiamxu/kit/cache.cacheModel]", false},
+		{Config{Type: TypeRedis}, nil, "", true},
+		{Config{Type: TypeMemory}, nil, "*cache.LRU[github.com/tiamxu/kit/cache.cacheModel]", false},
+		{Config{Type: TypeTiered}, client, "*cache.Tiered[github.com/tiamxu/kit/cache.cacheModel]", false},
+		{Config{Type: TypeTiered}, nil, "", true},
+		{Config{Type: TypeNoop}, nil, "cache.Noop[github.com/tiamxu/kit/cache.cacheModel]", false},
+		{Config{Type: "memcached"}, client, "", true},
+	}
+	for _, tt := range tests {
+		c, err := New[cacheModel](tt.cfg, tt.client)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("New(%+v) succeeded, want error", tt.cfg)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("New(%+v): %v", tt.cfg, err)
+		}
+		if got := fmt.Sprintf("%T", c); got != tt.want {
+			t.Errorf("New(%+v) = %s, want %s", tt.cfg, got, tt.want)
+		}
+		if closer, ok := c.(interface{ Close() error }); ok {
+			_ = closer.Close()
+		}
+	}
+}
+
+func TestRedisCacheMissAndAbsent(t *testing.T) {
+	client, _ := newTestClient(t, nil)
+	ctx := context.Background()
+	c := NewRedisCache(client)
+
+	if _, err := c.Get(ctx, "missing"); !errors.Is(err, ErrCacheMiss) {
+		t.Fatalf("Get missing error = %v, want ErrCacheMiss", err)
+	}
+	_ = c.Set(ctx, "empty", "", 60)
+	if v, err := c.Get(ctx, "empty"); err != nil || v != "" {
+		t.Fatalf("Get empty = %q, %v, want empty value", v, err)
+	}
+	_ = c.SetAbsent(ctx, "absent", 60)
+	if _, err := c.Get(ctx, "absent"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get absent error = %v, want ErrNotFound", err)
+	}
+	var m cacheModel
+	if _, err := c.GetObj(ctx, "absent", &m); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetObj absent error = %v, want ErrNotFound", err)
+	}
+	if ok, err := c.GetObj(ctx, "missing", &m); err != nil || ok {
+		t.Fatalf("GetObj missing = %v, %v, want false, nil", ok, err)
+	}
+}
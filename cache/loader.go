@@ -0,0 +1,225 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/tiamxu/kit/log"
+	"github.com/tiamxu/kit/redis"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	defaultLoadLockTTL  = 10 * time.Second
+	loadLockPollBackoff = 50 * time.Millisecond
+	defaultNegativeTTL  = time.Minute
+	loadDeltaSize       = 10000
+)
+
+var (
+	loadGroup singleflight.Group
+	// 每个 key 最近一次加载耗时，用于 XFetch 提前刷新；容量有限，与缓存同时过期
+	loadDeltas = NewLRU[time.Duration](loadDeltaSize)
+)
+
+// Loader 缓存未命中时加载数据
+type Loader[T any] func(ctx context.Context) (T, error)
+
+type loadOptions struct {
+	client      *redis.RedisClient
+	lockTTL     time.Duration
+	beta        float64
+	negativeTTL time.Duration
+	bloom       *redis.BloomFilter
+}
+
+// LoadOption GetOrLoad 配置项
+type LoadOption func(*loadOptions)
+
+// WithLoadLock 使用 Redis 锁保证多个进程中只有一个执行加载，其他进程等待缓存写入，最长等待 lockTTL(默认10s)
+func WithLoadLock(client *redis.RedisClient, lockTTL time.Duration) LoadOption {
+	return func(o *loadOptions) {
+		o.client = client
+		o.lockTTL = lockTTL
+	}
+}
+
+// WithEarlyRefresh 开启 XFetch 概率提前刷新，beta 越大越早刷新，通常取1
+// 需额外读取一次 TTL，仅对加载耗时较长的热点 key 使用
+func WithEarlyRefresh(beta float64) LoadOption {
+	return func(o *loadOptions) {
+		o.beta = beta
+	}
+}
+
+// WithNegativeTTL 设置不存在标记的过期时间，默认1m且不超过正常数据的 ttl
+func WithNegativeTTL(ttl time.Duration) LoadOption {
+	return func(o *loadOptions) {
+		o.negativeTTL = ttl
+	}
+}
+
+// WithBloom 读取缓存前先查询布隆过滤器，判定不存在时直接返回 ErrNotFound
+// 以缓存 key 作为元素查询，数据写入数据源时需同步 Add 该 key；过滤器查询失败时按可能存在处理
+func WithBloom(bf *redis.BloomFilter) LoadOption {
+	return func(o *loadOptions) {
+		o.bloom = bf
+	}
+}
+
+// GetOrLoad 读取缓存，未命中时调用 loader 加载并写入缓存
+// 同一进程内相同 key 的并发加载只执行一次；缓存读写失败时仍返回加载结果
+// loader 返回 ErrNotFound 时写入不存在标记，过期前的请求直接返回 ErrNotFound，避免穿透到数据源
+func GetOrLoad[T any](ctx context.Context, c Cache[T], key string, ttl time.Duration, loader Loader[T], opts ...LoadOption) (T, error) {
+	o := &loadOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.lockTTL <= 0 {
+		o.lockTTL = defaultLoadLockTTL
+	}
+	if o.negativeTTL <= 0 {
+		o.negativeTTL = defaultNegativeTTL
+	}
+	if ttl > 0 && ttl < o.negativeTTL {
+		o.negativeTTL = ttl
+	}
+	// 不同缓存实例中的同名 key 互不影响
+	flightKey := fmt.Sprintf("%p|%s", c, key)
+
+	var zero T
+	if o.bloom != nil {
+		exists, err := o.bloom.Exists(ctx, key)
+		if err != nil {
+			log.GetLogger().Warnf("cache bloom check %s failed: %v", key, err)
+		} else if !exists[0] {
+			return zero, ErrNotFound
+		}
+	}
+
+	v, err := c.Get(ctx, key)
+	if errors.Is(err, ErrNotFound) {
+		return zero, ErrNotFound
+	}
+	if err != nil && !errors.Is(err, ErrCacheMiss) {
+		log.GetLogger().Warnf("cache get %s failed, loading from source: %v", key, err)
+	}
+	if err == nil {
+		if o.beta > 0 && shouldRefreshEarly(ctx, c, key, flightKey, o.beta) {
+			// 后台刷新，当前请求直接返回缓存值
+			go func() {
+				_, _, _ = loadGroup.Do(flightKey, func() (interface{}, error) {
+					return load(context.WithoutCancel(ctx), c, key, flightKey, ttl, loader, o, false)
+				})
+			}()
+		}
+		return v, nil
+	}
+
+	ch := loadGroup.DoChan(flightKey, func() (interface{}, error) {
+		// 加载不随单个调用方取消而中断，其他等待者仍可使用结果
+		return load(context.WithoutCancel(ctx), c, key, flightKey, ttl, loader, o, true)
+	})
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return zero, res.Err
+		}
+		// T 为接口类型且 loader 返回 nil 时断言失败，返回零值
+		v, _ := res.Val.(T)
+		return v, nil
+	}
+}
+
+// load 加载并写入缓存，useLock 时先获取跨进程锁，未获取到则等待其他进程写入
+func load[T any](ctx context.Context, c Cache[T], key, flightKey string, ttl time.Duration,
+	loader Loader[T], o *loadOptions, useLock bool) (T, error) {
+	var zero T
+	if useLock && o.client != nil {
+		lock := o.client.NewMutex("cache:"+key, o.lockTTL)
+		_, err := lock.TryLock(ctx)
+		switch {
+		case err == nil:
+			defer func() {
+				if err := lock.Unlock(ctx); err != nil && !errors.Is(err, redis.ErrLockNotHeld) {
+					log.GetLogger().Warnf("cache load unlock %s failed: %v", key, err)
+				}
+			}()
+			// 获取锁前其他进程可能已写入
+			if v, err := c.Get(ctx, key); err == nil || errors.Is(err, ErrNotFound) {
+				return v, err
+			}
+		case errors.Is(err, redis.ErrLockNotObtained):
+			if v, ok, err := waitLoaded(ctx, c, key, o.lockTTL); ok {
+				return v, err
+			}
+			// 等待超时，自行加载
+		default:
+			log.GetLogger().Warnf("cache load lock %s failed, loading without lock: %v", key, err)
+		}
+	}
+
+	start := time.Now()
+	v, err := loader(ctx)
+	if errors.Is(err, ErrNotFound) {
+		if err := c.SetAbsent(ctx, key, o.negativeTTL); err != nil {
+			log.GetLogger().Warnf("cache set absent %s failed: %v", key, err)
+		}
+		return zero, ErrNotFound
+	}
+	if err != nil {
+		return v, err
+	}
+	if o.beta > 0 {
+		_ = loadDeltas.Set(ctx, flightKey, time.Since(start), ttl)
+	}
+
+	if err := c.Set(ctx, key, v, ttl); err != nil {
+		log.GetLogger().Warnf("cache set %s after load failed: %v", key, err)
+	}
+	return v, nil
+}
+
+// waitLoaded 轮询等待持有锁的进程写入缓存，写入的是不存在标记时返回 ErrNotFound
+func waitLoaded[T any](ctx context.Context, c Cache[T], key string, timeout time.Duration) (T, bool, error) {
+	var zero T
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		timer := time.NewTimer(loadLockPollBackoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return zero, false, nil
+		case <-timer.C:
+		}
+		v, err := c.Get(ctx, key)
+		if err == nil {
+			return v, true, nil
+		}
+		if errors.Is(err, ErrNotFound) {
+			return zero, true, ErrNotFound
+		}
+	}
+	return zero, false, nil
+}
+
+// shouldRefreshEarly XFetch: 剩余时间 <= -delta*beta*ln(rand) 时提前刷新
+// delta 为最近一次加载耗时，加载越慢、越接近过期，刷新概率越高
+func shouldRefreshEarly[T any](ctx context.Context, c Cache[T], key, flightKey string, beta float64) bool {
+	d, err := loadDeltas.Get(ctx, flightKey)
+	if err != nil {
+		return false
+	}
+	remaining, err := c.TTL(ctx, key)
+	if err != nil || remaining <= 0 {
+		return false
+	}
+	delta := float64(d)
+	return -delta*beta*math.Log(rand.Float64()) >= float64(remaining)
+}
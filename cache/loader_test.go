@@ -0,0 +1,185 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingLoader 统计调用次数，release 关闭前阻塞
+func countingLoader[T any](calls *atomic.Int32, release <-chan struct{}, v T, err error) Loader[T] {
+	return func(ctx context.Context) (T, error) {
+		calls.Add(1)
+		if release != nil {
+			<-release
+		}
+		return v, err
+	}
+}
+
+func waitCalls(t *testing.T, calls *atomic.Int32, want int32) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for calls.Load() < want && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := calls.Load(); got != want {
+		t.Fatalf("loader called %d times, want %d", got, want)
+	}
+}
+
+func TestGetOrLoadSingleflight(t *testing.T) {
+	c := NewLRU[string](0)
+	ctx := context.Background()
+	var calls atomic.Int32
+	release := make(chan struct{})
+	loader := countingLoader(&calls, release, "v", nil)
+
+	var wg sync.WaitGroup
+	results := make([]string, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			v, err := GetOrLoad(ctx, c, "k", time.Minute, loader)
+			if err != nil {
+				t.Errorf("GetOrLoad: %v", err)
+			}
+			results[i] = v
+		}(i)
+	}
+	waitCalls(t, &calls, 1)
+	close(release)
+	wg.Wait()
+
+	for i, v := range results {
+		if v != "v" {
+			t.Fatalf("result %d = %q, want v", i, v)
+		}
+	}
+	if v, err := GetOrLoad(ctx, c, "k", time.Minute, loader); err != nil || v != "v" || calls.Load() != 1 {
+		t.Fatalf("cached GetOrLoad = %q, %v after %d calls", v, err, calls.Load())
+	}
+}
+
+func TestGetOrLoadNotFound(t *testing.T) {
+	c := NewLRU[string](0)
+	ctx := context.Background()
+	var calls atomic.Int32
+	loader := countingLoader(&calls, nil, "", ErrNotFound)
+	for i := 0; i < 3; i++ {
+		if _, err := GetOrLoad(ctx, c, "missing", time.Minute, loader); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("GetOrLoad error = %v, want ErrNotFound", err)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("loader called %d times, want 1 before the absent marker expires", calls.Load())
+	}
+}
+
+// T 为接口类型时 loader 可返回 nil
+func TestGetOrLoadNilInterface(t *testing.T) {
+	c := NewLRU[any](0)
+	v, err := GetOrLoad(context.Background(), c, "k", time.Minute, func(ctx context.Context) (any, error) {
+		return nil, nil
+	})
+	if err != nil || v != nil {
+		t.Fatalf("GetOrLoad = %v, %v, want nil, nil", v, err)
+	}
+}
+
+// 其他进程持有加载锁时等待其写入缓存，不调用 loader
+func TestGetOrLoadWaitsForLockHolder(t *testing.T) {
+	client, _ := newTestClient(t, nil)
+	c := NewTypedRedis[string](client)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		write   func(key string) error
+		want    string
+		wantErr error
+	}{
+		{"value", func(key string) error { return c.Set(ctx, key, "from holder", time.Minute) }, "from holder", nil},
+		{"absent", func(key string) error { return c.SetAbsent(ctx, key, time.Minute) }, "", ErrNotFound},
+	}
+	for _, tt := range tests {
+		key := "wait:" + tt.name
+		holder := client.NewMutex("cache:"+key, time.Second)
+		if _, err := holder.TryLock(ctx); err != nil {
+			t.Fatalf("%s: holder TryLock: %v", tt.name, err)
+		}
+		time.AfterFunc(100*time.Millisecond, func() {
+			if err := tt.write(key); err != nil {
+				t.Errorf("%s: holder write: %v", tt.name, err)
+			}
+		})
+
+		var calls atomic.Int32
+		v, err := GetOrLoad(ctx, c, key, time.Minute, countingLoader(&calls, nil, "from loader", nil),
+			WithLoadLock(client, time.Second))
+		if v != tt.want || !errors.Is(err, tt.wantErr) || calls.Load() != 0 {
+			t.Errorf("%s: GetOrLoad = %q, %v after %d loader calls, want %q, %v without loading",
+				tt.name, v, err, calls.Load(), tt.want, tt.wantErr)
+		}
+		_ = holder.Unlock(ctx)
+	}
+}
+
+// 等待超过 lockTTL 仍未写入时自行加载
+func TestGetOrLoadLockWaitTimeout(t *testing.T) {
+	client, _ := newTestClient(t, nil)
+	c := NewTypedRedis[string](client)
+	ctx := context.Background()
+	holder := client.NewMutex("cache:slow", time.Second)
+	if _, err := holder.TryLock(ctx); err != nil {
+		t.Fatalf("holder TryLock: %v", err)
+	}
+	defer holder.Unlock(ctx)
+
+	var calls atomic.Int32
+	start := time.Now()
+	v, err := GetOrLoad(ctx, c, "slow", time.Minute, countingLoader(&calls, nil, "loaded", nil),
+		WithLoadLock(client, 200*time.Millisecond))
+	if err != nil || v != "loaded" || calls.Load() != 1 {
+		t.Fatalf("GetOrLoad = %q, %v after %d calls, want loaded after 1", v, err, calls.Load())
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Fatalf("loaded after %s, want to wait for lockTTL first", elapsed)
+	}
+}
+
+// 命中缓存时按 XFetch 概率在后台提前刷新，未开启时不刷新
+func TestGetOrLoadEarlyRefresh(t *testing.T) {
+	ctx := context.Background()
+	for _, tt := range []struct {
+		name  string
+		opts  []LoadOption
+		calls int32
+	}{
+		// beta 极大时刷新概率接近1
+		{"enabled", []LoadOption{WithEarlyRefresh(1e12)}, 2},
+		{"disabled", nil, 1},
+	} {
+		c := NewLRU[string](0)
+		var calls atomic.Int32
+		loader := func(ctx context.Context) (string, error) {
+			calls.Add(1)
+			time.Sleep(5 * time.Millisecond)
+			return "v", nil
+		}
+		for i := 0; i < 2; i++ {
+			if v, err := GetOrLoad(ctx, c, "hot", time.Hour, loader, tt.opts...); err != nil || v != "v" {
+				t.Fatalf("%s: GetOrLoad = %q, %v", tt.name, v, err)
+			}
+		}
+		waitCalls(t, &calls, tt.calls)
+		time.Sleep(50 * time.Millisecond)
+		if got := calls.Load(); got != tt.calls {
+			t.Fatalf("%s: loader called %d times, want %d", tt.name, got, tt.calls)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+const defaultLRUSize = 10000
+
+type lruEntry[T any] struct {
+	key       string
+	value     T
+	absent    bool
+	expiresAt time.Time
+}
+
+func (e *lruEntry[T]) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// LRU 进程内 LRU 缓存，超过容量时淘汰最久未访问的条目，过期条目在访问时清理
+type LRU[T any] struct {
+	size int
+
+	mu    sync.Mutex
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+// NewLRU 创建容量为 size 的 LRU 缓存，size<=0 时默认10000
+func NewLRU[T any](size int) *LRU[T] {
+	if size <= 0 {
+		size = defaultLRUSize
+	}
+	return &LRU[T]{size: size, ll: list.New(), items: make(map[string]*list.Element)}
+}
+
+func (c *LRU[T]) Get(_ context.Context, key string) (T, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, ok, absent := c.get(key, time.Now())
+	if absent {
+		return v, ErrNotFound
+	}
+	if !ok {
+		return v, ErrCacheMiss
+	}
+	return v, nil
+}
+
+func (c *LRU[T]) Set(_ context.Context, key string, value T, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set(key, value, false, ttl, time.Now())
+	return nil
+}
+
+func (c *LRU[T]) SetAbsent(_ context.Context, key string, ttl time.Duration) error {
+	var zero T
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set(key, zero, true, ttl, time.Now())
+	return nil
+}
+
+func (c *LRU[T]) Delete(_ context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		if el, ok := c.items[key]; ok {
+			c.remove(el)
+		}
+	}
+	return nil
+}
+
+func (c *LRU[T]) GetMulti(_ context.Context, keys []string) (map[string]T, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	result := make(map[string]T, len(keys))
+	for _, key := range keys {
+		if v, ok, _ := c.get(key, now); ok {
+			result[key] = v
+		}
+	}
+	return result, nil
+}
+
+func (c *LRU[T]) SetMulti(_ context.Context, items map[string]T, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for key, value := range items {
+		c.set(key, value, false, ttl, now)
+	}
+	return nil
+}
+
+func (c *LRU[T]) TTL(_ context.Context, key string) (time.Duration, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return 0, nil
+	}
+	e := el.Value.(*lruEntry[T])
+	now := time.Now()
+	if e.expired(now) {
+		c.remove(el)
+		return 0, nil
+	}
+	if e.expiresAt.IsZero() {
+		return NoExpiration, nil
+	}
+	return e.expiresAt.Sub(now), nil
+}
+
+// Len 返回当前条目数(含未清理的过期条目)
+func (c *LRU[T]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+// get 返回缓存值、是否命中以及是否记录为不存在
+func (c *LRU[T]) get(key string, now time.Time) (T, bool, bool) {
+	var zero T
+	el, ok := c.items[key]
+	if !ok {
+		return zero, false, false
+	}
+	e := el.Value.(*lruEntry[T])
+	if e.expired(now) {
+		c.remove(el)
+		return zero, false, false
+	}
+	c.ll.MoveToFront(el)
+	if e.absent {
+		return zero, false, true
+	}
+	return e.value, true, false
+}
+
+func (c *LRU[T]) set(key string, value T, absent bool, ttl time.Duration, now time.Time) {
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = now.Add(ttl)
+	}
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*lruEntry[T])
+		e.value, e.absent, e.expiresAt = value, absent, expiresAt
+		c.ll.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.ll.PushFront(&lruEntry[T]{key: key, value: value, absent: absent, expiresAt: expiresAt})
+	for c.ll.Len() > c.size {
+		c.remove(c.ll.Back())
+	}
+}
+
+func (c *LRU[T]) remove(el *list.Element) {
+	c.ll.Remove(el)
+	delete(c.items, el.Value.(*lruEntry[T]).key)
+}
@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Noop 不缓存任何数据，读取总是未命中
+type Noop[T any] struct{}
+
+// NewNoop 创建空缓存
+func NewNoop[T any]() Noop[T] {
+	return Noop[T]{}
+}
+
+func (Noop[T]) Get(context.Context, string) (T, error) {
+	var zero T
+	return zero, ErrCacheMiss
+}
+
+func (Noop[T]) Set(context.Context, string, T, time.Duration) error {
+	return nil
+}
+
+func (Noop[T]) SetAbsent(context.Context, string, time.Duration) error {
+	return nil
+}
+
+func (Noop[T]) Delete(context.Context, ...string) error {
+	return nil
+}
+
+func (Noop[T]) GetMulti(context.Context, []string) (map[string]T, error) {
+	return map[string]T{}, nil
+}
+
+func (Noop[T]) SetMulti(context.Context, map[string]T, time.Duration) error {
+	return nil
+}
+
+func (Noop[T]) TTL(context.Context, string) (time.Duration, error) {
+	return 0, nil
+}
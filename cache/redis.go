@@ -2,6 +2,7 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/tiamxu/kit/redis"
@@ -19,16 +20,25 @@ func (c *RedisCache) GetClient() *redis.RedisClient {
 	return c.client
 }
 
+// Get 读取字符串，key 不存在时返回 ErrCacheMiss，key 记录为不存在时返回 ErrNotFound
 func (c *RedisCache) Get(ctx context.Context, key string) (string, error) {
 	str, err := c.client.Get(ctx, key).Result()
 	if redis.IsRedisNil(err) {
-		return "", nil
+		return "", ErrCacheMiss
+	}
+	if err == nil && redis.IsAbsent([]byte(str)) {
+		return "", ErrNotFound
 	}
 	return str, err
 }
 
+// GetObj 读取对象，key 记录为不存在时返回 ErrNotFound
 func (c *RedisCache) GetObj(ctx context.Context, key string, model interface{}) (bool, error) {
-	return c.client.GetCacheToModel(ctx, key, model)
+	ok, err := c.client.GetCacheToModel(ctx, key, model)
+	if errors.Is(err, redis.ErrAbsent) {
+		return false, ErrNotFound
+	}
+	return ok, err
 }
 
 func (c *RedisCache) Set(ctx context.Context, key, value string, ttlSecond int32) error {
@@ -39,6 +49,11 @@ func (c *RedisCache) SetObj(ctx context.Context, key string, obj interface{}, tt
 	return c.client.SetModelToCache(ctx, key, obj, time.Second*time.Duration(ttlSecond))
 }
 
+// SetAbsent 记录 key 对应的数据不存在，之后 Get 与 GetObj 返回 ErrNotFound
+func (c *RedisCache) SetAbsent(ctx context.Context, key string, ttlSecond int32) error {
+	return c.client.SetAbsent(ctx, key, time.Second*time.Duration(ttlSecond))
+}
+
 func (c *RedisCache) Delete(ctx context.Context, key string) error {
 	return c.client.Del(ctx, key).Err()
 }
@@ -0,0 +1,232 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/tiamxu/kit/log"
+)
+
+const defaultSoftTTL = time.Minute
+
+// Entry SWR 缓存条目，记录写入时间用于判断是否过期
+type Entry[T any] struct {
+	Value T `json:"value" msgpack:"value"`
+	// 写入时间(unix 毫秒)
+	StoredAt int64 `json:"stored_at" msgpack:"stored_at"`
+}
+
+// age 返回条目写入至今的时长
+func (e Entry[T]) age(now time.Time) time.Duration {
+	return now.Sub(time.UnixMilli(e.StoredAt))
+}
+
+// SWRConfig stale-while-revalidate 配置
+type SWRConfig struct {
+	// 超过 SoftTTL 的数据视为过期，返回旧值并在后台刷新，默认1m
+	SoftTTL time.Duration `yaml:"soft_ttl"`
+	// 超过 HardTTL 的数据不再直接返回，需同步加载，默认 SoftTTL 的2倍
+	HardTTL time.Duration `yaml:"hard_ttl"`
+	// 超过 HardTTL 后加载失败时，在 Grace 时间内仍返回旧值，0表示不返回
+	Grace time.Duration `yaml:"grace"`
+	// OnEvent 每次计入 SWRStats 时回调，用于导出到 Prometheus 等监控系统，需并发安全
+	OnEvent func(event SWREvent) `yaml:"-"`
+}
+
+// SWREvent SWR 统计事件，与 SWRStats 字段一一对应
+type SWREvent string
+
+const (
+	// SWRFresh 未过期命中
+	SWRFresh SWREvent = "fresh"
+	// SWRStale 过期命中
+	SWRStale SWREvent = "stale"
+	// SWRMiss 同步加载
+	SWRMiss SWREvent = "miss"
+	// SWRRefresh 后台刷新
+	SWRRefresh SWREvent = "refresh"
+	// SWRRefreshError 后台刷新失败
+	SWRRefreshError SWREvent = "refresh_error"
+	// SWRStaleOnError 加载失败时返回旧值
+	SWRStaleOnError SWREvent = "stale_on_error"
+)
+
+// SWRStats SWR 缓存统计
+type SWRStats struct {
+	// 未过期命中
+	Fresh int64
+	// 过期命中，返回旧值并触发后台刷新
+	Stale int64
+	// 未命中或超过 HardTTL，同步加载
+	Misses int64
+	// 后台刷新次数与失败次数
+	Refreshes     int64
+	RefreshErrors int64
+	// 加载失败时返回旧值的次数
+	StaleOnError int64
+}
+
+// SWR 在 Cache 之上实现 stale-while-revalidate:
+// 数据过期后先返回旧值再后台刷新，数据源故障时在宽限期内继续返回旧值
+// 底层使用 Tiered 时，Redis 不可用期间仍可返回本地副本
+type SWR[T any] struct {
+	cache Cache[Entry[T]]
+	cfg   SWRConfig
+
+	fresh         atomic.Int64
+	stale         atomic.Int64
+	misses        atomic.Int64
+	refreshes     atomic.Int64
+	refreshErrors atomic.Int64
+	staleOnError  atomic.Int64
+}
+
+// NewSWR 基于 c 创建 SWR 缓存，c 可由 New[Entry[T]] 创建
+func NewSWR[T any](c Cache[Entry[T]], cfg SWRConfig) *SWR[T] {
+	if cfg.SoftTTL <= 0 {
+		cfg.SoftTTL = defaultSoftTTL
+	}
+	if cfg.HardTTL <= 0 {
+		cfg.HardTTL = 2 * cfg.SoftTTL
+	}
+	if cfg.HardTTL < cfg.SoftTTL {
+		cfg.HardTTL = cfg.SoftTTL
+	}
+	if cfg.Grace < 0 {
+		cfg.Grace = 0
+	}
+	return &SWR[T]{cache: c, cfg: cfg}
+}
+
+// Get 读取缓存，按数据新旧程度返回缓存值、返回旧值并后台刷新或同步调用 loader 加载
+// loader 返回 ErrNotFound 时写入不存在标记，过期时间为 SoftTTL(不超过1m)
+func (s *SWR[T]) Get(ctx context.Context, key string, loader Loader[T]) (T, error) {
+	var zero T
+	e, err := s.cache.Get(ctx, key)
+	if errors.Is(err, ErrNotFound) {
+		return zero, ErrNotFound
+	}
+	if err != nil && !errors.Is(err, ErrCacheMiss) {
+		log.GetLogger().Warnf("swr cache get %s failed, loading from source: %v", key, err)
+	}
+	if err != nil {
+		s.record(&s.misses, SWRMiss)
+		return s.loadShared(ctx, key, loader)
+	}
+
+	age := e.age(time.Now())
+	switch {
+	case age < s.cfg.SoftTTL:
+		s.record(&s.fresh, SWRFresh)
+		return e.Value, nil
+	case age < s.cfg.HardTTL:
+		s.record(&s.stale, SWRStale)
+		s.refresh(ctx, key, loader)
+		return e.Value, nil
+	}
+
+	s.record(&s.misses, SWRMiss)
+	v, err := s.loadShared(ctx, key, loader)
+	if err == nil || errors.Is(err, ErrNotFound) || ctx.Err() != nil {
+		return v, err
+	}
+	if age < s.cfg.HardTTL+s.cfg.Grace {
+		s.record(&s.staleOnError, SWRStaleOnError)
+		log.GetLogger().Warnf("swr load %s failed, serving stale value (age %s): %v", key, age, err)
+		return e.Value, nil
+	}
+	return v, err
+}
+
+// Set 写入缓存，写入时间为当前时间
+func (s *SWR[T]) Set(ctx context.Context, key string, value T) error {
+	e := Entry[T]{Value: value, StoredAt: time.Now().UnixMilli()}
+	return s.cache.Set(ctx, key, e, s.cfg.HardTTL+s.cfg.Grace)
+}
+
+func (s *SWR[T]) Delete(ctx context.Context, keys ...string) error {
+	return s.cache.Delete(ctx, keys...)
+}
+
+// Stats 返回统计数据
+func (s *SWR[T]) Stats() SWRStats {
+	return SWRStats{
+		Fresh:         s.fresh.Load(),
+		Stale:         s.stale.Load(),
+		Misses:        s.misses.Load(),
+		Refreshes:     s.refreshes.Load(),
+		RefreshErrors: s.refreshErrors.Load(),
+		StaleOnError:  s.staleOnError.Load(),
+	}
+}
+
+// record 计数并回调 OnEvent
+func (s *SWR[T]) record(counter *atomic.Int64, event SWREvent) {
+	counter.Add(1)
+	if s.cfg.OnEvent != nil {
+		s.cfg.OnEvent(event)
+	}
+}
+
+// refresh 后台刷新，相同 key 同时只有一个刷新任务，失败时保留旧值直到 HardTTL+Grace 后过期
+// 失败在任务内计数，其他调用方加入同一任务时也只计一次
+func (s *SWR[T]) refresh(ctx context.Context, key string, loader Loader[T]) {
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		_, _, _ = loadGroup.Do(s.flightKey(key), func() (interface{}, error) {
+			s.record(&s.refreshes, SWRRefresh)
+			v, err := s.load(ctx, key, loader)
+			if err != nil && !errors.Is(err, ErrNotFound) {
+				s.record(&s.refreshErrors, SWRRefreshError)
+				log.GetLogger().Warnf("swr refresh %s failed, keep serving stale value: %v", key, err)
+			}
+			return v, err
+		})
+	}()
+}
+
+// loadShared 同步加载，同一进程内相同 key 的并发加载只执行一次
+func (s *SWR[T]) loadShared(ctx context.Context, key string, loader Loader[T]) (T, error) {
+	var zero T
+	ch := loadGroup.DoChan(s.flightKey(key), func() (interface{}, error) {
+		return s.load(context.WithoutCancel(ctx), key, loader)
+	})
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return zero, res.Err
+		}
+		// T 为接口类型且 loader 返回 nil 时断言失败，返回零值
+		v, _ := res.Val.(T)
+		return v, nil
+	}
+}
+
+// load 调用 loader 并写入缓存，缓存写入失败时仍返回加载结果
+func (s *SWR[T]) load(ctx context.Context, key string, loader Loader[T]) (T, error) {
+	v, err := loader(ctx)
+	if errors.Is(err, ErrNotFound) {
+		ttl := min(s.cfg.SoftTTL, defaultNegativeTTL)
+		if err := s.cache.SetAbsent(ctx, key, ttl); err != nil {
+			log.GetLogger().Warnf("swr cache set absent %s failed: %v", key, err)
+		}
+		return v, ErrNotFound
+	}
+	if err != nil {
+		return v, err
+	}
+	if err := s.Set(ctx, key, v); err != nil {
+		log.GetLogger().Warnf("swr cache set %s after load failed: %v", key, err)
+	}
+	return v, nil
+}
+
+// flightKey 与 GetOrLoad 共用 singleflight 分组，以实例地址区分
+func (s *SWR[T]) flightKey(key string) string {
+	return fmt.Sprintf("swr|%p|%s", s, key)
+}
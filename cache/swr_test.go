@@ -0,0 +1,175 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var errSource = errors.New("source unavailable")
+
+// eventRecorder 记录 OnEvent 回调
+type eventRecorder struct {
+	mu     sync.Mutex
+	events map[SWREvent]int64
+}
+
+func (r *eventRecorder) record(e SWREvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.events == nil {
+		r.events = make(map[SWREvent]int64)
+	}
+	r.events[e]++
+}
+
+func (r *eventRecorder) count(e SWREvent) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.events[e]
+}
+
+// storeEntry 直接写入指定年龄的条目
+func storeEntry(t *testing.T, c Cache[Entry[string]], key, value string, age time.Duration) {
+	t.Helper()
+	e := Entry[string]{Value: value, StoredAt: time.Now().Add(-age).UnixMilli()}
+	if err := c.Set(context.Background(), key, e, time.Hour); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+}
+
+func TestSWRTransitions(t *testing.T) {
+	client, _ := newTestClient(t, nil)
+	backends := map[string]func() Cache[Entry[string]]{
+		"lru":   func() Cache[Entry[string]] { return NewLRU[Entry[string]](0) },
+		"redis": func() Cache[Entry[string]] { return NewTypedRedis[Entry[string]](client) },
+	}
+	cfg := SWRConfig{SoftTTL: time.Minute, HardTTL: 2 * time.Minute, Grace: time.Minute}
+	tests := []struct {
+		name      string
+		age       time.Duration
+		loadErr   error
+		want      string
+		wantErr   error
+		calls     int32
+		event     SWREvent
+		refreshed bool
+	}{
+		{"fresh", 30 * time.Second, nil, "old", nil, 0, SWRFresh, false},
+		{"stale refresh", 90 * time.Second, nil, "old", nil, 1, SWRStale, true},
+		{"stale refresh error", 90 * time.Second, errSource, "old", nil, 1, SWRRefreshError, false},
+		{"expired reload", 150 * time.Second, nil, "new", nil, 1, SWRMiss, true},
+		{"stale on error", 150 * time.Second, errSource, "old", nil, 1, SWRStaleOnError, false},
+		{"beyond grace", 200 * time.Second, errSource, "", errSource, 1, SWRMiss, false},
+		{"miss", -1, nil, "new", nil, 1, SWRMiss, true},
+	}
+	for name, newCache := range backends {
+		for _, tt := range tests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				ctx := context.Background()
+				var rec eventRecorder
+				c := newCache()
+				cfg := cfg
+				cfg.OnEvent = rec.record
+				s := NewSWR[string](c, cfg)
+				key := "swr:" + tt.name
+				_ = c.Delete(ctx, key)
+				if tt.age >= 0 {
+					storeEntry(t, c, key, "old", tt.age)
+				}
+
+				var calls atomic.Int32
+				v, err := s.Get(ctx, key, countingLoader(&calls, nil, "new", tt.loadErr))
+				if v != tt.want || !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Get = %q, %v, want %q, %v", v, err, tt.want, tt.wantErr)
+				}
+				waitCalls(t, &calls, tt.calls)
+				waitFor(t, func() bool { return rec.count(tt.event) == 1 })
+
+				if tt.refreshed {
+					waitFor(t, func() bool {
+						e, err := c.Get(ctx, key)
+						return err == nil && e.Value == "new"
+					})
+				}
+			})
+		}
+	}
+}
+
+func TestSWRStats(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU[Entry[string]](0)
+	s := NewSWR[string](c, SWRConfig{SoftTTL: time.Minute})
+	loader := func(ctx context.Context) (string, error) { return "v", nil }
+
+	if _, err := s.Get(ctx, "k", loader); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get(ctx, "k", loader); err != nil {
+		t.Fatal(err)
+	}
+	storeEntry(t, c, "k", "v", 90*time.Second)
+	if _, err := s.Get(ctx, "k", loader); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return s.Stats().Refreshes == 1 })
+	want := SWRStats{Fresh: 1, Stale: 1, Misses: 1, Refreshes: 1}
+	if got := s.Stats(); got != want {
+		t.Fatalf("Stats = %+v, want %+v", got, want)
+	}
+}
+
+// 同步加载加入刷新任务时，刷新失败仍计入 RefreshErrors
+func TestSWRRefreshErrorSharedFlight(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU[Entry[string]](0)
+	s := NewSWR[string](c, SWRConfig{SoftTTL: time.Minute})
+	storeEntry(t, c, "k", "old", 90*time.Second)
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	loader := countingLoader(&calls, release, "", errSource)
+	if v, err := s.Get(ctx, "k", loader); err != nil || v != "old" {
+		t.Fatalf("Get = %q, %v, want stale value", v, err)
+	}
+	waitCalls(t, &calls, 1)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := s.loadShared(ctx, "k", loader)
+		done <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	if err := <-done; !errors.Is(err, errSource) {
+		t.Fatalf("loadShared error = %v, want errSource", err)
+	}
+	waitFor(t, func() bool { return s.Stats().RefreshErrors == 1 })
+	if calls.Load() != 1 {
+		t.Fatalf("loader called %d times, want 1 shared call", calls.Load())
+	}
+}
+
+// T 为接口类型时 loader 可返回 nil
+func TestSWRNilInterface(t *testing.T) {
+	s := NewSWR[any](NewLRU[Entry[any]](0), SWRConfig{})
+	v, err := s.Get(context.Background(), "k", func(ctx context.Context) (any, error) { return nil, nil })
+	if err != nil || v != nil {
+		t.Fatalf("Get = %v, %v, want nil, nil", v, err)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before deadline")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
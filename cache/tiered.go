@@ -0,0 +1,204 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/tiamxu/kit/log"
+	"github.com/tiamxu/kit/redis"
+)
+
+const (
+	defaultLocalTTL          = time.Minute
+	defaultInvalidateChannel = "cache:invalidate"
+)
+
+// invalidation 失效通知，固定使用 json 编码，不受客户端 codec 配置影响
+type invalidation struct {
+	Source string   `json:"source"`
+	Keys   []string `json:"keys"`
+}
+
+// Tiered 两级缓存: 进程内 LRU 在前，Redis 在后
+// 任一实例写入或删除 key 时通过 pub/sub 通知其他实例删除本地副本；
+// 订阅断开期间错过的通知由本地副本的过期时间(LocalTTL)兜底
+type Tiered[T any] struct {
+	local    *LRU[T]
+	remote   *TypedRedis[T]
+	client   *redis.RedisClient
+	localTTL time.Duration
+	channel  string
+	id       string
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewTiered 创建两级缓存并订阅失效通知，使用 Size、LocalTTL 与 Channel 配置，不再使用时需调用 Close
+func NewTiered[T any](client *redis.RedisClient, cfg Config) *Tiered[T] {
+	if cfg.LocalTTL <= 0 {
+		cfg.LocalTTL = defaultLocalTTL
+	}
+	if cfg.Channel == "" {
+		// 频道名不会自动加前缀，默认频道按 KeyPrefix 隔离
+		cfg.Channel = client.KeyPrefix() + defaultInvalidateChannel
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &Tiered[T]{
+		local:    NewLRU[T](cfg.Size),
+		remote:   NewTypedRedis[T](client),
+		client:   client,
+		localTTL: cfg.LocalTTL,
+		channel:  cfg.Channel,
+		id:       instanceID(),
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+	go func() {
+		defer close(c.done)
+		err := client.SubscribeHandler(ctx, []string{c.channel}, c.onInvalidate)
+		if err != nil {
+			log.GetLogger().Errorf("tiered cache subscribe %s failed: %v", c.channel, err)
+		}
+	}()
+	return c
+}
+
+func (c *Tiered[T]) Get(ctx context.Context, key string) (T, error) {
+	v, err := c.local.Get(ctx, key)
+	if !errors.Is(err, ErrCacheMiss) {
+		return v, err
+	}
+	v, err = c.remote.Get(ctx, key)
+	if errors.Is(err, ErrNotFound) {
+		_ = c.local.SetAbsent(ctx, key, c.localTTL)
+		return v, err
+	}
+	if err != nil {
+		return v, err
+	}
+	_ = c.local.Set(ctx, key, v, c.localTTL)
+	return v, nil
+}
+
+func (c *Tiered[T]) Set(ctx context.Context, key string, value T, ttl time.Duration) error {
+	if err := c.remote.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	_ = c.local.Set(ctx, key, value, c.capTTL(ttl))
+	c.publish(ctx, key)
+	return nil
+}
+
+func (c *Tiered[T]) SetAbsent(ctx context.Context, key string, ttl time.Duration) error {
+	if err := c.remote.SetAbsent(ctx, key, ttl); err != nil {
+		return err
+	}
+	_ = c.local.SetAbsent(ctx, key, c.capTTL(ttl))
+	c.publish(ctx, key)
+	return nil
+}
+
+func (c *Tiered[T]) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	_ = c.local.Delete(ctx, keys...)
+	if err := c.remote.Delete(ctx, keys...); err != nil {
+		return err
+	}
+	c.publish(ctx, keys...)
+	return nil
+}
+
+func (c *Tiered[T]) GetMulti(ctx context.Context, keys []string) (map[string]T, error) {
+	result, _ := c.local.GetMulti(ctx, keys)
+	if len(result) == len(keys) {
+		return result, nil
+	}
+	missing := make([]string, 0, len(keys)-len(result))
+	for _, key := range keys {
+		if _, ok := result[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	remote, err := c.remote.GetMulti(ctx, missing)
+	if len(remote) > 0 {
+		_ = c.local.SetMulti(ctx, remote, c.localTTL)
+		for k, v := range remote {
+			result[k] = v
+		}
+	}
+	return result, err
+}
+
+func (c *Tiered[T]) SetMulti(ctx context.Context, items map[string]T, ttl time.Duration) error {
+	if len(items) == 0 {
+		return nil
+	}
+	if err := c.remote.SetMulti(ctx, items, ttl); err != nil {
+		return err
+	}
+	_ = c.local.SetMulti(ctx, items, c.capTTL(ttl))
+	keys := make([]string, 0, len(items))
+	for k := range items {
+		keys = append(keys, k)
+	}
+	c.publish(ctx, keys...)
+	return nil
+}
+
+// TTL 返回 Redis 中的剩余过期时间
+func (c *Tiered[T]) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return c.remote.TTL(ctx, key)
+}
+
+// Close 停止订阅失效通知
+func (c *Tiered[T]) Close() error {
+	c.cancel()
+	<-c.done
+	return nil
+}
+
+// capTTL 本地副本的过期时间不超过 LocalTTL 与 Redis 中的过期时间
+func (c *Tiered[T]) capTTL(ttl time.Duration) time.Duration {
+	if ttl > 0 && ttl < c.localTTL {
+		return ttl
+	}
+	return c.localTTL
+}
+
+// publish 通知其他实例删除本地副本，失败时仅记录日志，本地副本会在 LocalTTL 后过期
+func (c *Tiered[T]) publish(ctx context.Context, keys ...string) {
+	data, err := json.Marshal(invalidation{Source: c.id, Keys: keys})
+	if err == nil {
+		err = c.client.Publish(ctx, c.channel, data).Err()
+	}
+	if err != nil {
+		log.GetLogger().Warnf("tiered cache publish invalidation %v failed: %v", keys, err)
+	}
+}
+
+func (c *Tiered[T]) onInvalidate(ctx context.Context, msg *redis.Message) error {
+	var inv invalidation
+	if err := json.Unmarshal(msg.Payload, &inv); err != nil {
+		return fmt.Errorf("decode invalidation: %w", err)
+	}
+	if inv.Source == c.id {
+		return nil
+	}
+	return c.local.Delete(ctx, inv.Keys...)
+}
+
+func instanceID() string {
+	host, _ := os.Hostname()
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(b))
+}
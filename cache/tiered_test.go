@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tiamxu/kit/redis"
+)
+
+// waitSubscribers 等待 n 个实例完成订阅
+func waitSubscribers(t *testing.T, c *redis.RedisClient, channel string, n int64) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		subs, err := c.PubSubNumSub(context.Background(), channel).Result()
+		if err == nil && subs[channel] >= n {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("%d subscribers on %s not established", n, channel)
+}
+
+// waitLocalMiss 等待失效通知删除本地副本
+func waitLocalMiss[T any](t *testing.T, c *Tiered[T], key string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := c.local.Get(context.Background(), key); errors.Is(err, ErrCacheMiss) {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("local copy of %s not invalidated", key)
+}
+
+// 失效通知不受客户端 codec 影响，raw 编码无法处理结构体
+func TestTieredInvalidationWithRawCodec(t *testing.T) {
+	client, _ := newTestClient(t, &redis.Config{Codec: "raw"})
+	ctx := context.Background()
+	cfg := Config{Type: TypeTiered, LocalTTL: time.Hour}
+	a := NewTiered[string](client, cfg)
+	defer a.Close()
+	b := NewTiered[string](client, cfg)
+	defer b.Close()
+	waitSubscribers(t, client, defaultInvalidateChannel, 2)
+
+	if err := a.Set(ctx, "k", "v1", time.Hour); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if v, err := b.Get(ctx, "k"); err != nil || v != "v1" {
+		t.Fatalf("b.Get = %q, %v, want v1", v, err)
+	}
+
+	if err := a.Set(ctx, "k", "v2", time.Hour); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	waitLocalMiss(t, b, "k")
+	if v, err := b.Get(ctx, "k"); err != nil || v != "v2" {
+		t.Fatalf("b.Get after invalidation = %q, %v, want v2", v, err)
+	}
+
+	if err := a.Delete(ctx, "k"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	waitLocalMiss(t, b, "k")
+	if _, err := b.Get(ctx, "k"); !errors.Is(err, ErrCacheMiss) {
+		t.Fatalf("b.Get after Delete error = %v, want ErrCacheMiss", err)
+	}
+}
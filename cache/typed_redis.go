@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tiamxu/kit/redis"
+)
+
+// TypedRedis 基于 Redis 的泛型缓存，编码方式与 SetModelToCache 一致
+type TypedRedis[T any] struct {
+	client *redis.RedisClient
+}
+
+// NewTypedRedis 创建 Redis 泛型缓存
+func NewTypedRedis[T any](client *redis.RedisClient) *TypedRedis[T] {
+	return &TypedRedis[T]{client: client}
+}
+
+// Client 返回底层 Redis 客户端
+func (c *TypedRedis[T]) Client() *redis.RedisClient {
+	return c.client
+}
+
+func (c *TypedRedis[T]) Get(ctx context.Context, key string) (T, error) {
+	var v T
+	ok, err := c.client.GetCacheToModel(ctx, key, &v)
+	if errors.Is(err, redis.ErrAbsent) {
+		return v, ErrNotFound
+	}
+	if err == nil && !ok {
+		return v, ErrCacheMiss
+	}
+	return v, err
+}
+
+func (c *TypedRedis[T]) Set(ctx context.Context, key string, value T, ttl time.Duration) error {
+	return c.client.SetModelToCache(ctx, key, value, ttl)
+}
+
+func (c *TypedRedis[T]) SetAbsent(ctx context.Context, key string, ttl time.Duration) error {
+	return c.client.SetAbsent(ctx, key, ttl)
+}
+
+func (c *TypedRedis[T]) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return c.client.Del(ctx, keys...).Err()
+}
+
+func (c *TypedRedis[T]) GetMulti(ctx context.Context, keys []string) (map[string]T, error) {
+	result, _, err := redis.MGetModels[T](ctx, c.client, keys)
+	return result, err
+}
+
+func (c *TypedRedis[T]) SetMulti(ctx context.Context, items map[string]T, ttl time.Duration) error {
+	models := make(map[string]interface{}, len(items))
+	for k, v := range items {
+		models[k] = v
+	}
+	return c.client.MSetModels(ctx, models, ttl)
+}
+
+func (c *TypedRedis[T]) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := c.client.PTTL(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("TTL key=%s: %w", key, err)
+	}
+	switch ttl {
+	case -2:
+		return 0, nil
+	case -1:
+		return NoExpiration, nil
+	}
+	return ttl, nil
+}
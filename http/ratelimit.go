@@ -0,0 +1,93 @@
+package httpkit
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tiamxu/kit/log"
+	"github.com/tiamxu/kit/redis"
+)
+
+// RateLimitKeyFunc 从请求中提取限流 key，返回空字符串表示不限流
+type RateLimitKeyFunc func(c *gin.Context) string
+
+// KeyByClientIP 按客户端IP限流
+func KeyByClientIP() RateLimitKeyFunc {
+	return func(c *gin.Context) string {
+		return "ip:" + c.ClientIP()
+	}
+}
+
+// KeyByAPIKey 按请求头中的 API Key 限流，未携带时退化为按IP限流
+func KeyByAPIKey(header string) RateLimitKeyFunc {
+	return func(c *gin.Context) string {
+		if apiKey := c.GetHeader(header); apiKey != "" {
+			return "apikey:" + apiKey
+		}
+		return "ip:" + c.ClientIP()
+	}
+}
+
+// KeyByRoute 按路由限流，所有客户端共享同一额度
+func KeyByRoute() RateLimitKeyFunc {
+	return func(c *gin.Context) string {
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+		return "route:" + c.Request.Method + ":" + route
+	}
+}
+
+// RedisRateLimitMiddleware 基于 Redis 的分布式限流中间件，所有副本共享额度
+// Redis 不可用时放行请求，避免限流组件故障导致服务不可用
+func RedisRateLimitMiddleware(limiter redis.RateLimiter, keyFunc RateLimitKeyFunc) gin.HandlerFunc {
+	if keyFunc == nil {
+		keyFunc = KeyByClientIP()
+	}
+	return func(c *gin.Context) {
+		key := keyFunc(c)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		result, err := limiter.Allow(c.Request.Context(), key)
+		if err != nil {
+			log.GetLogger().WithFields(log.Fields{
+				"key":   key,
+				"path":  c.Request.URL.Path,
+				"error": err.Error(),
+			}).Warn("rate limiter unavailable, request allowed")
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error": gin.H{
+					"type":    "rate_limit_exceeded",
+					"message": "请求过于频繁，请稍后再试",
+					"code":    http.StatusTooManyRequests,
+				},
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// ceilSeconds 向上取整到秒
+func ceilSeconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,113 @@
+package httpkit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tiamxu/kit/redis"
+)
+
+// stubLimiter 返回预设结果并记录 key
+type stubLimiter struct {
+	result *redis.RateLimitResult
+	err    error
+	keys   []string
+}
+
+func (l *stubLimiter) Allow(_ context.Context, key string) (*redis.RateLimitResult, error) {
+	l.keys = append(l.keys, key)
+	return l.result, l.err
+}
+
+func TestRedisRateLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name       string
+		limiter    *stubLimiter
+		keyFunc    RateLimitKeyFunc
+		wantStatus int
+		wantKeys   int
+		headers    map[string]string
+	}{
+		{
+			name:       "allowed",
+			limiter:    &stubLimiter{result: &redis.RateLimitResult{Allowed: true, Limit: 10, Remaining: 9, ResetAfter: 1500 * time.Millisecond}},
+			wantStatus: http.StatusOK,
+			wantKeys:   1,
+			headers:    map[string]string{"X-RateLimit-Limit": "10", "X-RateLimit-Remaining": "9", "X-RateLimit-Reset": "2"},
+		},
+		{
+			name:       "rejected",
+			limiter:    &stubLimiter{result: &redis.RateLimitResult{Limit: 10, RetryAfter: 200 * time.Millisecond, ResetAfter: time.Second}},
+			wantStatus: http.StatusTooManyRequests,
+			wantKeys:   1,
+			headers:    map[string]string{"Retry-After": "1", "X-RateLimit-Remaining": "0"},
+		},
+		{
+			name:       "limiter unavailable",
+			limiter:    &stubLimiter{err: errors.New("connection refused")},
+			wantStatus: http.StatusOK,
+			wantKeys:   1,
+		},
+		{
+			name:       "empty key",
+			limiter:    &stubLimiter{err: errors.New("must not be called")},
+			keyFunc:    func(c *gin.Context) string { return "" },
+			wantStatus: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(RedisRateLimitMiddleware(tt.limiter, tt.keyFunc))
+			r.GET("/items/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items/1", nil))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if len(tt.limiter.keys) != tt.wantKeys {
+				t.Fatalf("limiter called %d times, want %d", len(tt.limiter.keys), tt.wantKeys)
+			}
+			for k, v := range tt.headers {
+				if got := w.Header().Get(k); got != v {
+					t.Errorf("header %s = %q, want %q", k, got, v)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimitKeyFuncs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var keys []string
+	r := gin.New()
+	r.GET("/items/:id", func(c *gin.Context) {
+		keys = append(keys, KeyByClientIP()(c), KeyByAPIKey("X-API-Key")(c), KeyByRoute()(c))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/items/1", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	req.Header.Set("X-API-Key", "k1")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	want := []string{
+		"ip:10.0.0.1", "ip:10.0.0.1", "route:GET:/items/:id",
+		"ip:10.0.0.1", "apikey:k1", "route:GET:/items/:id",
+	}
+	if len(keys) != len(want) {
+		t.Fatalf("keys = %v, want %v", keys, want)
+	}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Fatalf("keys = %v, want %v", keys, want)
+		}
+	}
+}
@@ -443,4 +443,8 @@ func ShutdownServer(srv *http.Server) {
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Fatalln("Server forced to shutdown:", err)
 	}
+	// 刷新异步日志缓冲区
+	if err := log.Sync(); err != nil {
+		fmt.Printf("Failed to sync logger: %v\n", err)
+	}
 }
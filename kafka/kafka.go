@@ -3,10 +3,10 @@ package kafka
 import (
 	"context"
 	"fmt"
-	"log"
 	"time"
 
 	"github.com/segmentio/kafka-go"
+	"github.com/tiamxu/kit/log"
 )
 
 // KafkaProducer 封装了使用segmentio/kafka-go的Kafka生产者
@@ -127,7 +127,8 @@ func (c *KafkaConsumer) ConsumeMessage() {
 		// 读取消息并处理可能的错误
 		message, err := c.reader.ReadMessage(context.Background())
 		if err != nil {
-			log.Printf("found error from kafka reader %v", err)
+			// 经由 kit/log 输出，配置采样后可抑制高频重复错误
+			log.GetLogger().Errorf("found error from kafka reader %v", err)
 			continue
 		}
 
@@ -0,0 +1,213 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	OverflowBlock      = "block"       // 缓冲区满时阻塞写入方(默认)
+	OverflowDropNewest = "drop_newest" // 缓冲区满时丢弃当前日志
+	OverflowDropOldest = "drop_oldest" // 缓冲区满时丢弃最旧的日志
+)
+
+// AsyncConfig 异步写入配置
+type AsyncConfig struct {
+	BufferSize    int           `yaml:"buffer_size"`    // 缓冲区可容纳的日志条数，默认4096
+	FlushInterval time.Duration `yaml:"flush_interval"` // 定时刷新间隔，默认1s
+	Overflow      string        `yaml:"overflow"`       // 溢出策略: block、drop_newest、drop_oldest
+}
+
+// asyncWriter 基于环形缓冲区的异步写入器，由后台协程批量写入底层 writer
+type asyncWriter struct {
+	out      io.Writer
+	overflow string
+	interval time.Duration
+
+	mu       sync.Mutex
+	notFull  *sync.Cond
+	notEmpty *sync.Cond
+	ring     [][]byte
+	head     int
+	count    int
+	closed   bool
+	flushing bool
+	dropped  int64
+
+	done chan struct{}
+}
+
+func newAsyncWriter(out io.Writer, cfg AsyncConfig) *asyncWriter {
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 4096
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Second
+	}
+	w := &asyncWriter{
+		out:      out,
+		overflow: cfg.Overflow,
+		interval: cfg.FlushInterval,
+		ring:     make([][]byte, cfg.BufferSize),
+		done:     make(chan struct{}),
+	}
+	w.notFull = sync.NewCond(&w.mu)
+	w.notEmpty = sync.NewCond(&w.mu)
+	go w.run()
+	go w.tick()
+	return w
+}
+
+// Write 将日志放入缓冲区，p 在返回后会被 logrus 复用，因此需要拷贝
+func (w *asyncWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	buf := make([]byte, len(p))
+	copy(buf, p)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return w.writeClosed(buf)
+	}
+	for w.count == len(w.ring) {
+		switch w.overflow {
+		case OverflowDropNewest:
+			w.dropped++
+			return len(p), nil
+		case OverflowDropOldest:
+			w.ring[w.head] = nil
+			w.head = (w.head + 1) % len(w.ring)
+			w.count--
+			w.dropped++
+		default:
+			w.notFull.Wait()
+			if w.closed {
+				return w.writeClosed(buf)
+			}
+		}
+	}
+	w.ring[(w.head+w.count)%len(w.ring)] = buf
+	w.count++
+	w.notEmpty.Signal()
+	return len(p), nil
+}
+
+// writeClosed Close 之后底层 writer 已关闭，改为直接写入标准错误，避免日志丢失及逐条报错
+func (w *asyncWriter) writeClosed(p []byte) (int, error) {
+	out := w.out
+	if out != os.Stdout && out != os.Stderr {
+		out = os.Stderr
+	}
+	return out.Write(p)
+}
+
+// run 后台协程，取出缓冲区中的全部日志批量写入
+func (w *asyncWriter) run() {
+	defer close(w.done)
+	for {
+		w.mu.Lock()
+		for w.count == 0 && !w.closed {
+			w.notEmpty.Wait()
+		}
+		if w.count == 0 && w.closed {
+			w.mu.Unlock()
+			return
+		}
+		batch := make([][]byte, 0, w.count)
+		for w.count > 0 {
+			batch = append(batch, w.ring[w.head])
+			w.ring[w.head] = nil
+			w.head = (w.head + 1) % len(w.ring)
+			w.count--
+		}
+		dropped := w.dropped
+		w.dropped = 0
+		w.flushing = true
+		w.notFull.Broadcast()
+		w.mu.Unlock()
+
+		if dropped > 0 {
+			fmt.Fprintf(w.out, "async log buffer overflow, dropped %d entries\n", dropped)
+		}
+		for _, b := range batch {
+			if _, err := w.out.Write(b); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to write to log, %v\n", err)
+			}
+		}
+
+		w.mu.Lock()
+		w.flushing = false
+		w.notFull.Broadcast()
+		w.mu.Unlock()
+	}
+}
+
+// tick 定时刷新底层 writer
+func (w *asyncWriter) tick() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			_ = syncWriter(w.out)
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// Sync 等待缓冲区中的日志全部写出后刷新底层 writer
+func (w *asyncWriter) Sync() error {
+	w.mu.Lock()
+	for (w.count > 0 || w.flushing) && !w.closed {
+		w.notFull.Wait()
+	}
+	w.mu.Unlock()
+	return syncWriter(w.out)
+}
+
+// Close 写出剩余日志并关闭底层 writer
+func (w *asyncWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.notEmpty.Broadcast()
+	w.notFull.Broadcast()
+	w.mu.Unlock()
+
+	<-w.done
+	if err := syncWriter(w.out); err != nil {
+		return err
+	}
+	return closeWriter(w.out)
+}
+
+func syncWriter(out io.Writer) error {
+	// 标准输出为终端或管道时 Sync 会返回错误，直接跳过
+	if out == os.Stdout || out == os.Stderr {
+		return nil
+	}
+	if s, ok := out.(interface{ Sync() error }); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+func closeWriter(out io.Writer) error {
+	// 标准输出不关闭
+	if out == os.Stdout || out == os.Stderr {
+		return nil
+	}
+	if c, ok := out.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
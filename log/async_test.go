@@ -0,0 +1,81 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// redirectStderr 将 os.Stderr 重定向到临时文件，返回读取内容的函数
+func redirectStderr(t *testing.T) func() string {
+	t.Helper()
+	f, err := os.CreateTemp(t.TempDir(), "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = f
+	t.Cleanup(func() {
+		os.Stderr = stderr
+		_ = f.Close()
+	})
+	return func() string {
+		data, err := os.ReadFile(f.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+}
+
+func TestAsyncWriterWriteAfterClose(t *testing.T) {
+	readStderr := redirectStderr(t)
+	name := filepath.Join(t.TempDir(), "app.log")
+	file, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := newAsyncWriter(file, AsyncConfig{BufferSize: 4})
+
+	if _, err := w.Write([]byte("before close\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if n, err := w.Write([]byte("after close\n")); err != nil || n != len("after close\n") {
+		t.Fatalf("Write after Close = %d, %v", n, err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("second Close: %v", err)
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "before close\n" {
+		t.Fatalf("file = %q, want only the entry written before Close", data)
+	}
+	if out := readStderr(); out != "after close\n" {
+		t.Fatalf("stderr = %q, want the entry written after Close", out)
+	}
+}
+
+func TestAsyncWriterSync(t *testing.T) {
+	var buf strings.Builder
+	w := newAsyncWriter(&buf, AsyncConfig{BufferSize: 2})
+	defer w.Close()
+	for i := 0; i < 10; i++ {
+		if _, err := w.Write([]byte("x")); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := w.Sync(); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if got := buf.String(); got != strings.Repeat("x", 10) {
+		t.Fatalf("written %q after Sync, want 10 entries", got)
+	}
+}
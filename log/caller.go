@@ -0,0 +1,146 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// StackKey 堆栈字段名
+	StackKey = "stack"
+	// ErrorChainKey 错误链字段名
+	ErrorChainKey = "error_chain"
+
+	maxCallerDepth = 32
+)
+
+var internalPackages = []string{
+	"github.com/sirupsen/logrus",
+	"github.com/tiamxu/kit/log",
+}
+
+// callerHook 修正调用方信息并附加堆栈与错误链
+// logrus 自带的 ReportCaller 只跳过 logrus 自身的栈帧，经 kit/log 包装函数调用时
+// 会把 log.Errorf 等包装函数误报为调用方
+type callerHook struct {
+	caller bool
+	stack  bool
+}
+
+func (h *callerHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *callerHook) Fire(entry *logrus.Entry) error {
+	if h.caller || (h.stack && entry.Level <= logrus.ErrorLevel) {
+		frames := callerFrames()
+		if h.caller && len(frames) > 0 {
+			entry.Caller = &frames[0]
+		}
+		if h.stack && entry.Level <= logrus.ErrorLevel {
+			stack := make([]string, 0, len(frames))
+			for _, f := range frames {
+				stack = append(stack, fmt.Sprintf("%s (%s:%d)", f.Function, f.File, f.Line))
+			}
+			entry.Data[StackKey] = stack
+		}
+	}
+
+	// WithError 传入的错误同样展开错误链
+	if _, exists := entry.Data[ErrorChainKey]; !exists {
+		if err, ok := entry.Data[logrus.ErrorKey].(error); ok {
+			if chain := errorChain(err); len(chain) > 1 {
+				entry.Data[ErrorChainKey] = chain
+			}
+		}
+	}
+	return nil
+}
+
+// callerFrames 返回跳过 logrus 与 kit/log 内部栈帧后的调用栈
+func callerFrames() []runtime.Frame {
+	pcs := make([]uintptr, maxCallerDepth)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var result []runtime.Frame
+	for {
+		f, more := frames.Next()
+		if len(result) > 0 || !isInternalFrame(f.Function) {
+			result = append(result, f)
+		}
+		if !more {
+			break
+		}
+	}
+	return result
+}
+
+// callerFrame 返回跳过 logrus 与 kit/log 内部栈帧后的第一个栈帧
+func callerFrame() (runtime.Frame, bool) {
+	pcs := make([]uintptr, maxCallerDepth)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		f, more := frames.Next()
+		if !isInternalFrame(f.Function) {
+			return f, true
+		}
+		if !more {
+			return runtime.Frame{}, false
+		}
+	}
+}
+
+func isInternalFrame(function string) bool {
+	pkg := packageName(function)
+	for _, p := range internalPackages {
+		if pkg == p {
+			return true
+		}
+	}
+	return false
+}
+
+// packageName 从完整函数名中取出包路径，如 github.com/a/b.(*T).F -> github.com/a/b
+func packageName(function string) string {
+	slash := strings.LastIndex(function, "/")
+	if dot := strings.Index(function[slash+1:], "."); dot >= 0 {
+		return function[:slash+1+dot]
+	}
+	return function
+}
+
+// errorChain 逐层展开错误，支持 errors.Join 产生的多错误
+func errorChain(err error) []string {
+	var chain []string
+	var walk func(error)
+	walk = func(e error) {
+		for e != nil {
+			chain = append(chain, e.Error())
+			if multi, ok := e.(interface{ Unwrap() []error }); ok {
+				for _, inner := range multi.Unwrap() {
+					walk(inner)
+				}
+				return
+			}
+			e = errors.Unwrap(e)
+		}
+	}
+	walk(err)
+	return chain
+}
+
+// formatEntry 按 fmt.Errorf 格式化消息，格式串中包含 %w 时将错误链写入 error_chain
+func formatEntry(format string, args ...interface{}) (*logrus.Entry, string) {
+	err := fmt.Errorf(format, args...)
+	entry := logrus.NewEntry(_defaultLogger)
+	if chain := errorChain(err); len(chain) > 1 {
+		entry = entry.WithField(ErrorChainKey, chain)
+	}
+	return entry, err.Error()
+}
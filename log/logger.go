@@ -21,12 +21,27 @@ type Config struct {
 	MaxBackups int    `yaml:"max_backups"` //最大文件个数
 	MaxAge     int    `yaml:"max_age"`     //最大天数
 	Compress   bool   `yaml:"compress"`
-	Type       string `yaml:"type"`   //日志存储类型:stdout、file、kafka
-	Format     string `yaml:"format"` //日志格式: text,json
+	Rotation   string `yaml:"rotation"` //轮转方式: size(默认)、daily、hourly
+	Symlink    bool   `yaml:"symlink"`  //按时间轮转时是否创建指向当前文件的软链接
+	Type       string `yaml:"type"`     //日志存储类型:stdout、file、kafka、otlp
+	Format     string `yaml:"format"`   //日志格式: text,json
+	// Sampling 高频日志采样配置，为空时不采样
+	Sampling *SamplingConfig `yaml:"sampling"`
+	// Async 异步写入配置，为空时同步写入
+	Async *AsyncConfig `yaml:"async"`
+	// ReportCaller 记录调用方文件与行号
+	ReportCaller bool `yaml:"report_caller"`
+	// StackTrace error 及以上级别附加调用堆栈
+	StackTrace bool `yaml:"stack_trace"`
+	// OTLP Type 为 otlp 时的导出配置
+	OTLP *OTLPConfig `yaml:"otlp"`
 }
 
 var (
 	_defaultLogger *logrus.Logger
+	_output        io.Writer
+	_otlpHook      *otlpHook
+	_sampler       *samplingFormatter
 	once           sync.Once
 )
 
@@ -44,17 +59,30 @@ func InitLogger(cfg *Config) error {
 		}
 		_defaultLogger.SetLevel(level)
 
+		// 调用方、堆栈与错误链
+		_defaultLogger.SetReportCaller(cfg.ReportCaller)
+		_defaultLogger.AddHook(&callerHook{caller: cfg.ReportCaller, stack: cfg.StackTrace})
+
 		// 设置日志格式
+		var formatter logrus.Formatter
 		if cfg.Format == "json" {
-			_defaultLogger.SetFormatter(&logrus.JSONFormatter{
+			formatter = &logrus.JSONFormatter{
 				TimestampFormat: defaultTimestampFormat,
-			})
+			}
 		} else {
-			_defaultLogger.SetFormatter(&logrus.TextFormatter{
+			formatter = &logrus.TextFormatter{
 				TimestampFormat: defaultTimestampFormat,
 				FullTimestamp:   true,
-			})
+			}
 		}
+		// 设置日志采样
+		var sampler *samplingFormatter
+		if cfg.Sampling != nil {
+			sampler = newSamplingFormatter(_defaultLogger, formatter, *cfg.Sampling)
+			formatter = sampler
+			_sampler = sampler
+		}
+		_defaultLogger.SetFormatter(formatter)
 
 		// 设置输出
 		var output io.Writer
@@ -64,12 +92,38 @@ func InitLogger(cfg *Config) error {
 				fmt.Printf("Failed to setup file output: %v, fallback to stdout\n", err)
 				output = os.Stdout
 			}
+		case "otlp":
+			if cfg.OTLP == nil {
+				fmt.Println("Missing otlp config, fallback to stdout")
+				output = os.Stdout
+				break
+			}
+			if _otlpHook, err = newOTLPHook(*cfg.OTLP); err != nil {
+				fmt.Printf("Failed to setup otlp output: %v, fallback to stdout\n", err)
+				output = os.Stdout
+				break
+			}
+			_defaultLogger.AddHook(_otlpHook)
+			// 日志经 hook 导出，本地不再输出
+			output = io.Discard
+			// hook 在格式化之前执行，采样改由 hook 完成，避免重复计数
+			if sampler != nil {
+				_otlpHook.sampler = sampler
+				_defaultLogger.SetFormatter(sampler.Formatter)
+			}
 		case "stdout", "":
 			output = os.Stdout
 		default:
 			output = os.Stdout
 		}
+		// 异步写入
+		if cfg.Async != nil {
+			output = newAsyncWriter(output, *cfg.Async)
+		}
+		_output = output
 		_defaultLogger.SetOutput(output)
+		// Fatal 退出前刷新缓冲区
+		logrus.RegisterExitHandler(func() { _ = Close() })
 	})
 	return nil
 }
@@ -88,6 +142,11 @@ func setupFileOutput(cfg *Config) (io.Writer, error) {
 		return nil, fmt.Errorf("create log directory failed: %w", err)
 	}
 
+	// 按时间轮转，同时受 MaxSize 限制
+	if cfg.Rotation == RotationDaily || cfg.Rotation == RotationHourly {
+		return newRotateWriter(cfg)
+	}
+
 	fileName := filepath.Join(cfg.FilePath, cfg.FileName)
 	// 使用 lumberjack 进行日志轮转
 	return &lumberjack.Logger{
@@ -97,26 +156,35 @@ func setupFileOutput(cfg *Config) (io.Writer, error) {
 		MaxAge:     cfg.MaxAge,     // 保留的天数
 		Compress:   cfg.Compress,   // 是否压缩
 	}, nil
+}
 
-	// 设置日志轮转时间
-	// options := []rotatelogs.Option{
-	// 	rotatelogs.WithMaxAge(time.Duration(cfg.MaxAge) * 24 * time.Hour),
-	// 	rotatelogs.WithRotationTime(24 * time.Hour),
-	// }
-
-	// if cfg.MaxBackups > 0 {
-	// 	options = append(options, rotatelogs.WithRotationCount(uint(cfg.MaxBackups)))
-	// }
-
-	// writer, err := rotatelogs.New(
-	// 	logPath+".%Y%m%d",
-	// 	options...,
-	// )
-	// if err != nil {
-	// 	return nil, fmt.Errorf("create rotate logs failed: %w", err)
-	// }
+// Sync 将缓冲区中的日志写出并刷新到磁盘
+func Sync() error {
+	if _otlpHook != nil {
+		_ = _otlpHook.Sync()
+	}
+	if _output == nil {
+		return nil
+	}
+	return syncWriter(_output)
+}
 
-	// return writer, nil
+// Close 写出剩余日志并关闭日志输出，一般在进程退出前调用
+func Close() error {
+	// 先停止采样，最后一个周期的汇总随其余日志一起写出
+	if _sampler != nil {
+		_sampler.Close()
+	}
+	if _otlpHook != nil {
+		_ = _otlpHook.Close()
+	}
+	if _output == nil {
+		return nil
+	}
+	if err := syncWriter(_output); err != nil {
+		return err
+	}
+	return closeWriter(_output)
 }
 
 // GetLogger 获取logger实例
@@ -159,7 +227,7 @@ func DefaultLogger() *logrus.Logger {
 		})
 		_defaultLogger.SetOutput(os.Stdout)
 		_defaultLogger.SetLevel(logrus.TraceLevel)
-		// _defaultLogger.SetReportCaller(true)
+		_defaultLogger.AddHook(&callerHook{})
 	})
 	return _defaultLogger
 }
@@ -207,7 +275,11 @@ func Infoln(args ...interface{}) {
 }
 
 func Warnf(format string, args ...interface{}) {
-	_defaultLogger.Warnf(format, args...)
+	if !_defaultLogger.IsLevelEnabled(logrus.WarnLevel) {
+		return
+	}
+	entry, msg := formatEntry(format, args...)
+	entry.Warn(msg)
 }
 
 func Warnln(args ...interface{}) {
@@ -215,7 +287,11 @@ func Warnln(args ...interface{}) {
 }
 
 func Errorf(format string, args ...interface{}) {
-	_defaultLogger.Errorf(format, args...)
+	if !_defaultLogger.IsLevelEnabled(logrus.ErrorLevel) {
+		return
+	}
+	entry, msg := formatEntry(format, args...)
+	entry.Error(msg)
 }
 
 func Errorln(args ...interface{}) {
@@ -223,7 +299,8 @@ func Errorln(args ...interface{}) {
 }
 
 func Panicf(format string, args ...interface{}) {
-	_defaultLogger.Panicf(format, args...)
+	entry, msg := formatEntry(format, args...)
+	entry.Panic(msg)
 }
 
 func Panicln(args ...interface{}) {
@@ -231,7 +308,8 @@ func Panicln(args ...interface{}) {
 }
 
 func Fatalf(format string, args ...interface{}) {
-	_defaultLogger.Fatalf(format, args...)
+	entry, msg := formatEntry(format, args...)
+	entry.Fatal(msg)
 }
 
 func Fatalln(args ...interface{}) {
@@ -0,0 +1,435 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// OTLPConfig OpenTelemetry 日志导出配置，使用 OTLP/HTTP JSON 协议
+type OTLPConfig struct {
+	// Endpoint collector 地址，如 http://localhost:4318，未包含路径时自动追加 /v1/logs
+	Endpoint string `yaml:"endpoint"`
+	// Headers 附加请求头，如鉴权信息
+	Headers map[string]string `yaml:"headers"`
+	// ServiceName 对应资源属性 service.name
+	ServiceName string `yaml:"service_name"`
+	// ServiceVersion 对应资源属性 service.version
+	ServiceVersion string `yaml:"service_version"`
+	// Environment 对应资源属性 deployment.environment
+	Environment string `yaml:"environment"`
+	// ResourceAttributes 其他资源属性
+	ResourceAttributes map[string]string `yaml:"resource_attributes"`
+	// BatchSize 单次发送的最大日志条数，默认512
+	BatchSize int `yaml:"batch_size"`
+	// QueueSize 待发送队列长度，队列满时丢弃，默认2048
+	QueueSize int `yaml:"queue_size"`
+	// FlushInterval 定时发送间隔，默认1s
+	FlushInterval time.Duration `yaml:"flush_interval"`
+	// Timeout 单次请求超时，默认10s
+	Timeout time.Duration `yaml:"timeout"`
+	// MaxRetries 发送失败后的最大重试次数，默认3
+	MaxRetries int `yaml:"max_retries"`
+	// RetryBackoff 首次重试间隔，之后指数增长，默认500ms
+	RetryBackoff time.Duration `yaml:"retry_backoff"`
+}
+
+const (
+	otlpLogsPath   = "/v1/logs"
+	otlpScopeName  = "github.com/tiamxu/kit/log"
+	otlpMaxBackoff = 30 * time.Second
+)
+
+// otlpHook 将 logrus 日志转换为 OTLP LogRecord 并批量发送
+type otlpHook struct {
+	cfg      OTLPConfig
+	client   *http.Client
+	resource otlpResource
+	// sampler 不为空时只导出采样保留的日志
+	sampler *samplingFormatter
+
+	queue   chan otlpLogRecord
+	flushCh chan chan struct{}
+	closeCh chan struct{}
+	done    chan struct{}
+	closed  atomic.Bool
+	dropped atomic.Int64
+	once    sync.Once
+}
+
+func newOTLPHook(cfg OTLPConfig) (*otlpHook, error) {
+	if cfg.Endpoint == "" {
+		return nil, fmt.Errorf("otlp endpoint cannot be empty")
+	}
+	if !strings.HasSuffix(cfg.Endpoint, otlpLogsPath) {
+		cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/") + otlpLogsPath
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 512
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 2048
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Second
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = 3
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = 500 * time.Millisecond
+	}
+
+	h := &otlpHook{
+		cfg:      cfg,
+		client:   &http.Client{Timeout: cfg.Timeout},
+		resource: newOTLPResource(cfg),
+		queue:    make(chan otlpLogRecord, cfg.QueueSize),
+		flushCh:  make(chan chan struct{}),
+		closeCh:  make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go h.run()
+	return h, nil
+}
+
+func (h *otlpHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire 转换日志并放入队列，不阻塞调用方
+func (h *otlpHook) Fire(entry *logrus.Entry) error {
+	if h.closed.Load() {
+		return nil
+	}
+	if h.sampler != nil && !h.sampler.allow(entry) {
+		return nil
+	}
+	select {
+	case h.queue <- newOTLPLogRecord(entry):
+	default:
+		h.dropped.Add(1)
+	}
+	return nil
+}
+
+func (h *otlpHook) run() {
+	defer close(h.done)
+	ticker := time.NewTicker(h.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]otlpLogRecord, 0, h.cfg.BatchSize)
+	flush := func() {
+		if len(batch) > 0 {
+			h.export(batch)
+			batch = make([]otlpLogRecord, 0, h.cfg.BatchSize)
+		}
+		if dropped := h.dropped.Swap(0); dropped > 0 {
+			fmt.Fprintf(os.Stderr, "otlp log queue overflow, dropped %d entries\n", dropped)
+		}
+	}
+	// drain 取出队列中已有的全部日志
+	drain := func() {
+		for {
+			select {
+			case r := <-h.queue:
+				batch = append(batch, r)
+				if len(batch) >= h.cfg.BatchSize {
+					flush()
+				}
+			default:
+				flush()
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case r := <-h.queue:
+			batch = append(batch, r)
+			if len(batch) >= h.cfg.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case ack := <-h.flushCh:
+			drain()
+			close(ack)
+		case <-h.closeCh:
+			drain()
+			return
+		}
+	}
+}
+
+// Sync 立即发送队列中的日志
+func (h *otlpHook) Sync() error {
+	if h.closed.Load() {
+		return nil
+	}
+	ack := make(chan struct{})
+	select {
+	case h.flushCh <- ack:
+		<-ack
+	case <-h.done:
+	}
+	return nil
+}
+
+// Close 发送剩余日志并停止后台协程
+func (h *otlpHook) Close() error {
+	h.once.Do(func() {
+		h.closed.Store(true)
+		close(h.closeCh)
+	})
+	<-h.done
+	return nil
+}
+
+// export 发送一批日志，失败时按指数退避重试
+func (h *otlpHook) export(records []otlpLogRecord) {
+	body, err := json.Marshal(otlpLogsRequest{
+		ResourceLogs: []otlpResourceLogs{{
+			Resource: h.resource,
+			ScopeLogs: []otlpScopeLogs{{
+				Scope:      otlpScope{Name: otlpScopeName},
+				LogRecords: records,
+			}},
+		}},
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "otlp marshal logs failed: %v\n", err)
+		return
+	}
+
+	backoff := h.cfg.RetryBackoff
+	for i := 0; i <= h.cfg.MaxRetries; i++ {
+		retryable, err := h.send(body)
+		if err == nil {
+			return
+		}
+		if !retryable || i == h.cfg.MaxRetries {
+			fmt.Fprintf(os.Stderr, "otlp export %d logs failed: %v\n", len(records), err)
+			return
+		}
+		time.Sleep(backoff)
+		if backoff *= 2; backoff > otlpMaxBackoff {
+			backoff = otlpMaxBackoff
+		}
+	}
+}
+
+func (h *otlpHook) send(body []byte) (retryable bool, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), h.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.cfg.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range h.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		// 网络错误可重试
+		return true, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode == http.StatusBadGateway,
+		resp.StatusCode == http.StatusServiceUnavailable,
+		resp.StatusCode == http.StatusGatewayTimeout:
+		return true, fmt.Errorf("otlp collector returned %s", resp.Status)
+	default:
+		return false, fmt.Errorf("otlp collector returned %s", resp.Status)
+	}
+}
+
+// OTLP/HTTP JSON 数据结构，字段定义参见 opentelemetry-proto logs/v1
+type otlpLogsRequest struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+type otlpResourceLogs struct {
+	Resource  otlpResource    `json:"resource"`
+	ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeLogs struct {
+	Scope      otlpScope       `json:"scope"`
+	LogRecords []otlpLogRecord `json:"logRecords"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano         string         `json:"timeUnixNano"`
+	ObservedTimeUnixNano string         `json:"observedTimeUnixNano"`
+	SeverityNumber       int            `json:"severityNumber"`
+	SeverityText         string         `json:"severityText"`
+	Body                 otlpAnyValue   `json:"body"`
+	Attributes           []otlpKeyValue `json:"attributes,omitempty"`
+	TraceID              string         `json:"traceId,omitempty"`
+	SpanID               string         `json:"spanId,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string         `json:"stringValue,omitempty"`
+	BoolValue   *bool           `json:"boolValue,omitempty"`
+	IntValue    *string         `json:"intValue,omitempty"`
+	DoubleValue *float64        `json:"doubleValue,omitempty"`
+	ArrayValue  *otlpArrayValue `json:"arrayValue,omitempty"`
+}
+
+type otlpArrayValue struct {
+	Values []otlpAnyValue `json:"values"`
+}
+
+func newOTLPResource(cfg OTLPConfig) otlpResource {
+	var attrs []otlpKeyValue
+	add := func(k, v string) {
+		if v != "" {
+			attrs = append(attrs, otlpKeyValue{Key: k, Value: otlpString(v)})
+		}
+	}
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = "unknown_service"
+	}
+	add("service.name", serviceName)
+	add("service.version", cfg.ServiceVersion)
+	add("deployment.environment", cfg.Environment)
+	if host, err := os.Hostname(); err == nil {
+		add("host.name", host)
+	}
+	for k, v := range cfg.ResourceAttributes {
+		add(k, v)
+	}
+	return otlpResource{Attributes: attrs}
+}
+
+func newOTLPLogRecord(entry *logrus.Entry) otlpLogRecord {
+	severity, text := otlpSeverity(entry.Level)
+	r := otlpLogRecord{
+		TimeUnixNano:         strconv.FormatInt(entry.Time.UnixNano(), 10),
+		ObservedTimeUnixNano: strconv.FormatInt(time.Now().UnixNano(), 10),
+		SeverityNumber:       severity,
+		SeverityText:         text,
+		Body:                 otlpString(entry.Message),
+	}
+	for k, v := range entry.Data {
+		r.Attributes = append(r.Attributes, otlpKeyValue{Key: k, Value: otlpValue(v)})
+	}
+	if entry.Caller != nil {
+		r.Attributes = append(r.Attributes,
+			otlpKeyValue{Key: "code.function", Value: otlpString(entry.Caller.Function)},
+			otlpKeyValue{Key: "code.filepath", Value: otlpString(entry.Caller.File)},
+			otlpKeyValue{Key: "code.lineno", Value: otlpValue(entry.Caller.Line)},
+		)
+	}
+	if entry.Context != nil {
+		if sc := trace.SpanContextFromContext(entry.Context); sc.IsValid() {
+			r.TraceID = sc.TraceID().String()
+			r.SpanID = sc.SpanID().String()
+		}
+	}
+	return r
+}
+
+// otlpSeverity 映射 logrus 级别到 OTel SeverityNumber
+func otlpSeverity(level logrus.Level) (int, string) {
+	switch level {
+	case logrus.TraceLevel:
+		return 1, "TRACE"
+	case logrus.DebugLevel:
+		return 5, "DEBUG"
+	case logrus.InfoLevel:
+		return 9, "INFO"
+	case logrus.WarnLevel:
+		return 13, "WARN"
+	case logrus.ErrorLevel:
+		return 17, "ERROR"
+	case logrus.FatalLevel:
+		return 21, "FATAL"
+	default:
+		return 24, "PANIC"
+	}
+}
+
+func otlpString(s string) otlpAnyValue {
+	return otlpAnyValue{StringValue: &s}
+}
+
+func otlpInt(i int64) otlpAnyValue {
+	s := strconv.FormatInt(i, 10)
+	return otlpAnyValue{IntValue: &s}
+}
+
+func otlpValue(v interface{}) otlpAnyValue {
+	switch val := v.(type) {
+	case string:
+		return otlpString(val)
+	case bool:
+		return otlpAnyValue{BoolValue: &val}
+	case int:
+		return otlpInt(int64(val))
+	case int32:
+		return otlpInt(int64(val))
+	case int64:
+		return otlpInt(val)
+	case uint32:
+		return otlpInt(int64(val))
+	case float32:
+		f := float64(val)
+		return otlpAnyValue{DoubleValue: &f}
+	case float64:
+		return otlpAnyValue{DoubleValue: &val}
+	case error:
+		return otlpString(val.Error())
+	case []string:
+		values := make([]otlpAnyValue, 0, len(val))
+		for _, s := range val {
+			values = append(values, otlpString(s))
+		}
+		return otlpAnyValue{ArrayValue: &otlpArrayValue{Values: values}}
+	case fmt.Stringer:
+		return otlpString(val.String())
+	default:
+		return otlpString(fmt.Sprintf("%v", val))
+	}
+}
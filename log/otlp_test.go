@@ -0,0 +1,270 @@
+package log
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// otlpReceiver 进程内 OTLP/HTTP 接收端，按顺序返回 statuses 中的状态码，之后返回200
+type otlpReceiver struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	statuses []int
+	requests []otlpLogsRequest
+	attempts int
+}
+
+func newOTLPReceiver(t *testing.T, statuses ...int) *otlpReceiver {
+	t.Helper()
+	r := &otlpReceiver{statuses: statuses}
+	r.Server = httptest.NewServer(http.HandlerFunc(r.handle))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *otlpReceiver) handle(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.attempts++
+	if req.URL.Path != otlpLogsPath || req.Header.Get("Content-Type") != "application/json" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if len(r.statuses) > 0 {
+		status := r.statuses[0]
+		r.statuses = r.statuses[1:]
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+	}
+	body, _ := io.ReadAll(req.Body)
+	var logs otlpLogsRequest
+	if err := json.Unmarshal(body, &logs); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	r.requests = append(r.requests, logs)
+}
+
+// batches 返回每次成功请求中的日志条数
+func (r *otlpReceiver) batches() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sizes := make([]int, 0, len(r.requests))
+	for _, req := range r.requests {
+		n := 0
+		for _, rl := range req.ResourceLogs {
+			for _, sl := range rl.ScopeLogs {
+				n += len(sl.LogRecords)
+			}
+		}
+		sizes = append(sizes, n)
+	}
+	return sizes
+}
+
+func (r *otlpReceiver) attemptCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.attempts
+}
+
+func newTestOTLPLogger(t *testing.T, cfg OTLPConfig) (*logrus.Logger, *otlpHook) {
+	t.Helper()
+	if cfg.FlushInterval == 0 {
+		cfg.FlushInterval = time.Hour
+	}
+	if cfg.RetryBackoff == 0 {
+		cfg.RetryBackoff = time.Millisecond
+	}
+	h, err := newOTLPHook(cfg)
+	if err != nil {
+		t.Fatalf("newOTLPHook: %v", err)
+	}
+	t.Cleanup(func() { _ = h.Close() })
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	logger.SetLevel(logrus.TraceLevel)
+	logger.AddHook(h)
+	return logger, h
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before timeout")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func attributeMap(attrs []otlpKeyValue) map[string]string {
+	m := make(map[string]string, len(attrs))
+	for _, kv := range attrs {
+		if kv.Value.StringValue != nil {
+			m[kv.Key] = *kv.Value.StringValue
+		}
+	}
+	return m
+}
+
+func TestOTLPExportResourceLogs(t *testing.T) {
+	recv := newOTLPReceiver(t)
+	logger, h := newTestOTLPLogger(t, OTLPConfig{
+		Endpoint:       recv.URL,
+		ServiceName:    "order",
+		ServiceVersion: "1.2.3",
+		Environment:    "staging",
+	})
+
+	logger.WithField("order_id", "42").Warn("payment timeout")
+	if err := h.Sync(); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+
+	recv.mu.Lock()
+	defer recv.mu.Unlock()
+	if len(recv.requests) != 1 || len(recv.requests[0].ResourceLogs) != 1 {
+		t.Fatalf("requests = %+v, want one resourceLogs", recv.requests)
+	}
+	rl := recv.requests[0].ResourceLogs[0]
+	res := attributeMap(rl.Resource.Attributes)
+	for k, want := range map[string]string{
+		"service.name":           "order",
+		"service.version":        "1.2.3",
+		"deployment.environment": "staging",
+	} {
+		if res[k] != want {
+			t.Errorf("resource attribute %s = %q, want %q", k, res[k], want)
+		}
+	}
+	if len(rl.ScopeLogs) != 1 || rl.ScopeLogs[0].Scope.Name != otlpScopeName {
+		t.Fatalf("scopeLogs = %+v", rl.ScopeLogs)
+	}
+	records := rl.ScopeLogs[0].LogRecords
+	if len(records) != 1 {
+		t.Fatalf("got %d log records, want 1", len(records))
+	}
+	r := records[0]
+	if r.Body.StringValue == nil || *r.Body.StringValue != "payment timeout" {
+		t.Errorf("body = %+v, want payment timeout", r.Body)
+	}
+	if r.SeverityNumber != 13 || r.SeverityText != "WARN" {
+		t.Errorf("severity = %d %s, want 13 WARN", r.SeverityNumber, r.SeverityText)
+	}
+	if attributeMap(r.Attributes)["order_id"] != "42" {
+		t.Errorf("attributes = %+v, want order_id=42", r.Attributes)
+	}
+}
+
+func TestOTLPSeverity(t *testing.T) {
+	tests := []struct {
+		level  logrus.Level
+		number int
+		text   string
+	}{
+		{logrus.TraceLevel, 1, "TRACE"},
+		{logrus.DebugLevel, 5, "DEBUG"},
+		{logrus.InfoLevel, 9, "INFO"},
+		{logrus.WarnLevel, 13, "WARN"},
+		{logrus.ErrorLevel, 17, "ERROR"},
+		{logrus.FatalLevel, 21, "FATAL"},
+		{logrus.PanicLevel, 24, "PANIC"},
+	}
+	for _, tt := range tests {
+		number, text := otlpSeverity(tt.level)
+		if number != tt.number || text != tt.text {
+			t.Errorf("otlpSeverity(%s) = %d %s, want %d %s", tt.level, number, text, tt.number, tt.text)
+		}
+	}
+}
+
+func TestOTLPBatching(t *testing.T) {
+	recv := newOTLPReceiver(t)
+	logger, h := newTestOTLPLogger(t, OTLPConfig{Endpoint: recv.URL, BatchSize: 3})
+
+	for i := 0; i < 7; i++ {
+		logger.Info("batch")
+	}
+	// 满 BatchSize 立即发送，剩余1条等待 Sync
+	waitFor(t, func() bool { return len(recv.batches()) == 2 })
+	if err := h.Sync(); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if got := recv.batches(); len(got) != 3 || got[0] != 3 || got[1] != 3 || got[2] != 1 {
+		t.Fatalf("batches after Sync = %v, want [3 3 1]", got)
+	}
+
+	logger.Info("before close")
+	logger.Info("before close")
+	if err := h.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if got := recv.batches(); len(got) != 4 || got[3] != 2 {
+		t.Fatalf("batches after Close = %v, want last batch of 2", got)
+	}
+	// 关闭后的日志直接丢弃
+	logger.Info("after close")
+	if got := recv.batches(); len(got) != 4 {
+		t.Fatalf("batches after logging on closed hook = %v", got)
+	}
+}
+
+func TestOTLPRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		attempts int
+		exported int
+	}{
+		{"retry on 503", []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable}, 3, 1},
+		{"give up after max retries", []int{503, 503, 503, 503}, 3, 0},
+		{"no retry on 400", []int{http.StatusBadRequest}, 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recv := newOTLPReceiver(t, tt.statuses...)
+			logger, h := newTestOTLPLogger(t, OTLPConfig{Endpoint: recv.URL, MaxRetries: 2})
+
+			logger.Error("retry me")
+			if err := h.Sync(); err != nil {
+				t.Fatalf("Sync: %v", err)
+			}
+			if got := recv.attemptCount(); got != tt.attempts {
+				t.Errorf("attempts = %d, want %d", got, tt.attempts)
+			}
+			if got := len(recv.batches()); got != tt.exported {
+				t.Errorf("exported batches = %d, want %d", got, tt.exported)
+			}
+		})
+	}
+}
+
+func TestOTLPSampling(t *testing.T) {
+	recv := newOTLPReceiver(t)
+	logger, h := newTestOTLPLogger(t, OTLPConfig{Endpoint: recv.URL})
+	h.sampler = newSamplingFormatter(logger, &logrus.TextFormatter{},
+		SamplingConfig{Initial: 2, Thereafter: -1, Interval: time.Hour})
+	t.Cleanup(h.sampler.Close)
+
+	for i := 0; i < 10; i++ {
+		logger.Error("kafka write failed")
+	}
+	if err := h.Sync(); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if got := recv.batches(); len(got) != 1 || got[0] != 2 {
+		t.Fatalf("batches = %v, want one batch of 2 sampled entries", got)
+	}
+}
@@ -0,0 +1,311 @@
+package log
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	RotationSize   = "size"   // 仅按大小轮转(lumberjack)
+	RotationDaily  = "daily"  // 按天轮转
+	RotationHourly = "hourly" // 按小时轮转
+)
+
+const compressSuffix = ".gz"
+
+// rotateWriter 按时间轮转的日志文件，同时支持按大小切分
+// 文件名格式: app.log.20060102[.N] 或 app.log.2006010215[.N]，
+// 开启 symlink 时 app.log 指向当前正在写入的文件
+type rotateWriter struct {
+	dir        string
+	name       string
+	layout     string
+	maxSize    int64
+	maxBackups int
+	maxAge     time.Duration
+	compress   bool
+	symlink    bool
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	period string
+	seq    int
+
+	millCh   chan struct{}
+	millOnce sync.Once
+}
+
+func newRotateWriter(cfg *Config) (*rotateWriter, error) {
+	w := &rotateWriter{
+		dir:        cfg.FilePath,
+		name:       cfg.FileName,
+		maxSize:    int64(cfg.MaxSize) * 1024 * 1024,
+		maxBackups: cfg.MaxBackups,
+		maxAge:     time.Duration(cfg.MaxAge) * 24 * time.Hour,
+		compress:   cfg.Compress,
+		symlink:    cfg.Symlink,
+	}
+	switch cfg.Rotation {
+	case RotationDaily:
+		w.layout = "20060102"
+	case RotationHourly:
+		w.layout = "2006010215"
+	default:
+		return nil, fmt.Errorf("unsupported log rotation: %s", cfg.Rotation)
+	}
+	return w, nil
+}
+
+// Write 实现 io.Writer
+func (w *rotateWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	period := time.Now().Format(w.layout)
+	switch {
+	case w.file == nil || period != w.period:
+		if err := w.rotate(period, 0, int64(len(p))); err != nil {
+			return 0, err
+		}
+	case w.maxSize > 0 && w.size+int64(len(p)) > w.maxSize:
+		if err := w.rotate(period, w.seq+1, int64(len(p))); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Sync 将文件内容刷到磁盘
+func (w *rotateWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	return w.file.Sync()
+}
+
+// Close 关闭当前文件
+func (w *rotateWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.closeFile()
+}
+
+func (w *rotateWriter) closeFile() error {
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+func (w *rotateWriter) rotate(period string, seq int, writeLen int64) error {
+	if err := w.closeFile(); err != nil {
+		return err
+	}
+	if err := w.open(period, seq, writeLen); err != nil {
+		return err
+	}
+	w.mill()
+	return nil
+}
+
+// open 打开指定周期的文件，已存在且空间不足或已被压缩时顺延序号(兼容进程重启)
+func (w *rotateWriter) open(period string, seq int, writeLen int64) error {
+	for {
+		filename := w.filename(period, seq)
+		if _, err := os.Stat(filename + compressSuffix); err == nil {
+			seq++
+			continue
+		}
+		info, err := os.Stat(filename)
+		if err == nil && w.maxSize > 0 && info.Size()+writeLen > w.maxSize {
+			seq++
+			continue
+		}
+
+		f, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return fmt.Errorf("open log file failed: %w", err)
+		}
+		var size int64
+		if info != nil {
+			size = info.Size()
+		}
+		w.file, w.size, w.period, w.seq = f, size, period, seq
+		if w.symlink {
+			w.link(filename)
+		}
+		return nil
+	}
+}
+
+func (w *rotateWriter) filename(period string, seq int) string {
+	name := w.name + "." + period
+	if seq > 0 {
+		name = fmt.Sprintf("%s.%d", name, seq)
+	}
+	return filepath.Join(w.dir, name)
+}
+
+// link 更新 current 软链接，先创建临时链接再原子替换
+// 同名的普通文件(如之前按大小轮转时写入的日志)先重命名保留，按备份文件参与清理
+func (w *rotateWriter) link(target string) {
+	linkName := filepath.Join(w.dir, w.name)
+	if info, err := os.Lstat(linkName); err == nil && info.Mode()&os.ModeSymlink == 0 {
+		backup := linkName + "." + info.ModTime().Format("20060102150405") + ".bak"
+		if err := os.Rename(linkName, backup); err != nil {
+			fmt.Fprintf(os.Stderr, "move existing log file %s aside failed, skip symlink: %v\n", linkName, err)
+			return
+		}
+	}
+	tmp := linkName + ".tmp"
+	_ = os.Remove(tmp)
+	if err := os.Symlink(filepath.Base(target), tmp); err != nil {
+		fmt.Fprintf(os.Stderr, "create log symlink failed: %v\n", err)
+		return
+	}
+	if err := os.Rename(tmp, linkName); err != nil {
+		fmt.Fprintf(os.Stderr, "rename log symlink failed: %v\n", err)
+	}
+}
+
+// mill 异步执行压缩与清理，同一时间只有一个清理任务
+func (w *rotateWriter) mill() {
+	w.millOnce.Do(func() {
+		w.millCh = make(chan struct{}, 1)
+		go func() {
+			for range w.millCh {
+				if err := w.millRun(); err != nil {
+					fmt.Fprintf(os.Stderr, "log rotation cleanup failed: %v\n", err)
+				}
+			}
+		}()
+	})
+	select {
+	case w.millCh <- struct{}{}:
+	default:
+	}
+}
+
+type rotatedFile struct {
+	path    string
+	modTime time.Time
+}
+
+func (w *rotateWriter) millRun() error {
+	w.mu.Lock()
+	current := ""
+	if w.file != nil {
+		current = w.file.Name()
+	}
+	w.mu.Unlock()
+
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return err
+	}
+	prefix := w.name + "."
+	var files []rotatedFile
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) || name == w.name+".tmp" {
+			continue
+		}
+		path := filepath.Join(w.dir, name)
+		if path == current {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || info.Mode()&os.ModeSymlink != 0 {
+			continue
+		}
+		files = append(files, rotatedFile{path: path, modTime: info.ModTime()})
+	}
+	// 新文件在前
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.After(files[j].modTime) })
+
+	cutoff := time.Now().Add(-w.maxAge)
+	var remaining []rotatedFile
+	// 当前正在写入的文件不计入保留个数
+	for i, f := range files {
+		if (w.maxBackups > 0 && i >= w.maxBackups) || (w.maxAge > 0 && f.modTime.Before(cutoff)) {
+			if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			continue
+		}
+		remaining = append(remaining, f)
+	}
+
+	if !w.compress {
+		return nil
+	}
+	for _, f := range remaining {
+		if strings.HasSuffix(f.path, compressSuffix) {
+			continue
+		}
+		if err := compressFile(f.path, f.path+compressSuffix); err != nil {
+			if errors.Is(err, fs.ErrExist) {
+				// 不覆盖已有的压缩文件，保留源文件
+				fmt.Fprintf(os.Stderr, "skip compressing %s: %v\n", f.path, err)
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// compressFile gzip 压缩文件并删除源文件，dst 已存在时返回 fs.ErrExist
+func compressFile(src, dst string) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, info.Mode())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			out.Close()
+			os.Remove(dst)
+		}
+	}()
+
+	gz := gzip.NewWriter(out)
+	if _, err = io.Copy(gz, in); err != nil {
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		return err
+	}
+	if err = out.Close(); err != nil {
+		return err
+	}
+	// 保留原文件的修改时间，保证按时间清理的顺序
+	_ = os.Chtimes(dst, info.ModTime(), info.ModTime())
+	return os.Remove(src)
+}
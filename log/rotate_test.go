@@ -0,0 +1,91 @@
+package log
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestRotateWriter(t *testing.T, maxSize int, compress, symlink bool) *rotateWriter {
+	t.Helper()
+	w, err := newRotateWriter(&Config{
+		FilePath: t.TempDir(),
+		FileName: "app.log",
+		MaxSize:  maxSize,
+		Compress: compress,
+		Symlink:  symlink,
+		Rotation: RotationDaily,
+	})
+	if err != nil {
+		t.Fatalf("newRotateWriter: %v", err)
+	}
+	t.Cleanup(func() { _ = w.Close() })
+	return w
+}
+
+// 同一周期内重启时，已压缩的序号不能再次使用
+func TestRotateOpenSkipsCompressedSeq(t *testing.T) {
+	w := newTestRotateWriter(t, 1, true, false)
+	period := time.Now().Format(w.layout)
+	archived := w.filename(period, 0) + compressSuffix
+	if err := os.WriteFile(archived, []byte("archived"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := w.Write([]byte("after restart\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if w.seq != 1 {
+		t.Fatalf("seq = %d, want 1", w.seq)
+	}
+	if got, _ := os.ReadFile(archived); string(got) != "archived" {
+		t.Fatalf("archive overwritten: %q", got)
+	}
+}
+
+func TestCompressFileKeepsExistingDst(t *testing.T) {
+	dir := t.TempDir()
+	src, dst := filepath.Join(dir, "app.log.1"), filepath.Join(dir, "app.log.1.gz")
+	if err := os.WriteFile(src, []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dst, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := compressFile(src, dst); !errors.Is(err, fs.ErrExist) {
+		t.Fatalf("compressFile error = %v, want fs.ErrExist", err)
+	}
+	if got, _ := os.ReadFile(dst); string(got) != "old" {
+		t.Fatalf("dst overwritten: %q", got)
+	}
+	if _, err := os.Stat(src); err != nil {
+		t.Fatalf("src removed: %v", err)
+	}
+}
+
+func TestRotateLinkMovesRegularFileAside(t *testing.T) {
+	w := newTestRotateWriter(t, 0, false, true)
+	linkName := filepath.Join(w.dir, w.name)
+	if err := os.WriteFile(linkName, []byte("lumberjack"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := w.Write([]byte("rotated\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	info, err := os.Lstat(linkName)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("%s is not a symlink: %v", linkName, err)
+	}
+	backups, _ := filepath.Glob(linkName + ".*.bak")
+	if len(backups) != 1 {
+		t.Fatalf("backups = %v, want one", backups)
+	}
+	if got, _ := os.ReadFile(backups[0]); !strings.HasPrefix(string(got), "lumberjack") {
+		t.Fatalf("backup content = %q", got)
+	}
+}
@@ -0,0 +1,166 @@
+package log
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SamplingConfig 日志采样配置
+// 每个周期内同一级别、同一调用位置的日志先记录前 Initial 条，之后每 Thereafter 条记录一条，
+// 周期结束时按被采样的级别(最高为 Error)输出一条汇总日志报告被丢弃的条数
+// 按调用位置而非消息内容区分，参数不同(如携带不同 err)的同一条日志也会被合并采样
+type SamplingConfig struct {
+	Initial    int           `yaml:"initial"`    // 每周期内完整记录的条数，默认100
+	Thereafter int           `yaml:"thereafter"` // 超出后每隔多少条记录一条，默认100，<0 表示全部丢弃
+	Interval   time.Duration `yaml:"interval"`   // 采样周期，默认1s
+}
+
+const samplingSummaryMessage = "log sampling suppressed entries"
+
+type samplingKey struct {
+	level logrus.Level
+	// caller 调用位置 file:line，无法获取时为消息内容
+	caller string
+}
+
+type samplingCounter struct {
+	total      int
+	suppressed int
+	// message 本周期内第一条日志的内容，用于汇总
+	message string
+}
+
+// samplingFormatter 在格式化阶段对日志进行采样，被丢弃的日志返回空内容
+// logrus 的 hook 无法阻止日志输出，因此采样放在 Formatter 中实现
+type samplingFormatter struct {
+	logrus.Formatter
+	logger *logrus.Logger
+	cfg    SamplingConfig
+
+	mu       sync.Mutex
+	counters map[samplingKey]*samplingCounter
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func newSamplingFormatter(logger *logrus.Logger, formatter logrus.Formatter, cfg SamplingConfig) *samplingFormatter {
+	if cfg.Initial <= 0 {
+		cfg.Initial = 100
+	}
+	if cfg.Thereafter == 0 {
+		cfg.Thereafter = 100
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second
+	}
+	f := &samplingFormatter{
+		Formatter: formatter,
+		logger:    logger,
+		cfg:       cfg,
+		counters:  make(map[samplingKey]*samplingCounter),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go f.run()
+	return f
+}
+
+// Close 停止周期汇总，停止前输出当前周期的汇总
+func (f *samplingFormatter) Close() {
+	f.stopOnce.Do(func() { close(f.stop) })
+	<-f.done
+}
+
+func (f *samplingFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	if !f.allow(entry) {
+		return nil, nil
+	}
+	return f.Formatter.Format(entry)
+}
+
+// allow 汇总日志本身不参与采样
+func (f *samplingFormatter) allow(entry *logrus.Entry) bool {
+	return entry.Message == samplingSummaryMessage || f.sample(entry)
+}
+
+// sample 判断当前日志是否需要输出
+func (f *samplingFormatter) sample(entry *logrus.Entry) bool {
+	key := samplingKey{level: entry.Level, caller: entryCaller(entry)}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, ok := f.counters[key]
+	if !ok {
+		c = &samplingCounter{message: entry.Message}
+		f.counters[key] = c
+	}
+	c.total++
+	if c.total <= f.cfg.Initial {
+		return true
+	}
+	if f.cfg.Thereafter > 0 && (c.total-f.cfg.Initial)%f.cfg.Thereafter == 0 {
+		return true
+	}
+	c.suppressed++
+	return false
+}
+
+// run 周期性重置计数并输出汇总，Close 时退出
+func (f *samplingFormatter) run() {
+	defer close(f.done)
+	ticker := time.NewTicker(f.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			f.flush()
+		case <-f.stop:
+			f.flush()
+			return
+		}
+	}
+}
+
+// flush 重置计数并输出汇总
+func (f *samplingFormatter) flush() {
+	f.mu.Lock()
+	counters := f.counters
+	f.counters = make(map[samplingKey]*samplingCounter, len(counters))
+	f.mu.Unlock()
+
+	// 汇总日志需在释放锁之后输出，避免与 Format 互相等待
+	for key, c := range counters {
+		if c.suppressed == 0 {
+			continue
+		}
+		// 与被采样的日志同级输出，保证不会被级别过滤；Panic/Fatal 降为 Error，避免汇总触发退出
+		level := key.level
+		if level < logrus.ErrorLevel {
+			level = logrus.ErrorLevel
+		}
+		f.logger.WithFields(Fields{
+			"sampled_level":   key.level.String(),
+			"sampled_caller":  key.caller,
+			"sampled_message": c.message,
+			"suppressed":      c.suppressed,
+			"total":           c.total,
+			"interval":        f.cfg.Interval.String(),
+		}).Log(level, samplingSummaryMessage)
+	}
+}
+
+// entryCaller 返回日志的调用位置，开启 ReportCaller 时直接使用 entry.Caller
+func entryCaller(entry *logrus.Entry) string {
+	if entry.Caller != nil {
+		return fmt.Sprintf("%s:%d", entry.Caller.File, entry.Caller.Line)
+	}
+	if f, ok := callerFrame(); ok {
+		return fmt.Sprintf("%s:%d", f.File, f.Line)
+	}
+	return entry.Message
+}
@@ -0,0 +1,82 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func newTestSamplingLogger(t *testing.T, level logrus.Level, cfg SamplingConfig) (*logrus.Logger, *samplingFormatter, *bytes.Buffer) {
+	t.Helper()
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&buf)
+	logger.SetLevel(level)
+	// 测试代码与 kit/log 同包，由 logrus 记录调用位置以区分调用点
+	logger.SetReportCaller(true)
+	f := newSamplingFormatter(logger, &logrus.JSONFormatter{}, cfg)
+	t.Cleanup(f.Close)
+	logger.SetFormatter(f)
+	return logger, f, &buf
+}
+
+// 同一调用位置参数不同的日志合并采样，不同调用位置分别计数
+func TestSamplingVaryingArguments(t *testing.T) {
+	logger, f, buf := newTestSamplingLogger(t, logrus.InfoLevel,
+		SamplingConfig{Initial: 2, Thereafter: -1, Interval: time.Hour})
+
+	for i := 0; i < 10; i++ {
+		logger.Errorf("found error from kafka reader %v", fmt.Errorf("offset %d: %w", i, errors.New("broken pipe")))
+	}
+	for i := 0; i < 3; i++ {
+		logger.Errorf("another call site %d", i)
+	}
+	if got := strings.Count(buf.String(), "kafka reader"); got != 2 {
+		t.Fatalf("kafka errors logged %d times, want 2:\n%s", got, buf)
+	}
+	if got := strings.Count(buf.String(), "another call site"); got != 2 {
+		t.Fatalf("second call site logged %d times, want 2", got)
+	}
+
+	buf.Reset()
+	f.Close()
+	out := buf.String()
+	if !strings.Contains(out, `"suppressed":8`) || !strings.Contains(out, `"suppressed":1`) {
+		t.Fatalf("summary = %s, want suppressed 8 and 1", out)
+	}
+	if !strings.Contains(out, "found error from kafka reader") || !strings.Contains(out, "sampling_test.go:") {
+		t.Fatalf("summary missing message or caller: %s", out)
+	}
+}
+
+// 日志级别为 Error 时汇总仍会输出
+func TestSamplingSummaryLevel(t *testing.T) {
+	logger, f, buf := newTestSamplingLogger(t, logrus.ErrorLevel,
+		SamplingConfig{Initial: 1, Thereafter: -1, Interval: time.Hour})
+	for i := 0; i < 5; i++ {
+		logger.Errorf("db timeout after %dms", i)
+	}
+	buf.Reset()
+	f.Close()
+	if out := buf.String(); !strings.Contains(out, samplingSummaryMessage) || !strings.Contains(out, `"level":"error"`) {
+		t.Fatalf("summary = %q, want error level summary", out)
+	}
+}
+
+func TestSamplingCloseStopsTicker(t *testing.T) {
+	before := runtime.NumGoroutine()
+	for i := 0; i < 10; i++ {
+		f := newSamplingFormatter(logrus.New(), &logrus.TextFormatter{}, SamplingConfig{Interval: time.Millisecond})
+		f.Close()
+		f.Close()
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Fatalf("goroutines grew from %d to %d after Close", before, after)
+	}
+}
@@ -0,0 +1,194 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 少于该数量时串行编解码，避免协程调度开销
+const parallelCodecThreshold = 16
+
+// MGetModels 批量读取 SetModelToCache 写入的模型，返回命中的结果与未命中的 key
+// 集群模式下按 slot 分组后通过 pipeline 发送 MGET，解压与反序列化并行执行；
+// 解码失败的 key 视为未命中，错误合并后返回；SetAbsent 标记的 key 既不在结果中也不在未命中列表中
+func MGetModels[T any](ctx context.Context, r *RedisClient, keys []string) (map[string]T, []string, error) {
+	result := make(map[string]T, len(keys))
+	if len(keys) == 0 {
+		return result, nil, nil
+	}
+
+	groups := r.groupKeysBySlot(keys)
+	cmds := make([]*redis.SliceCmd, 0, len(groups))
+	_, err := r.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, group := range groups {
+			cmds = append(cmds, pipe.MGet(ctx, group...))
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, nil, fmt.Errorf("MGetModels: %w", err)
+	}
+
+	type item struct {
+		key   string
+		value []byte
+	}
+	var (
+		items   []item
+		missing []string
+	)
+	for i, cmd := range cmds {
+		for j, v := range cmd.Val() {
+			key := groups[i][j]
+			s, ok := v.(string)
+			if !ok {
+				missing = append(missing, key)
+				continue
+			}
+			if IsAbsent([]byte(s)) {
+				continue
+			}
+			items = append(items, item{key: key, value: []byte(s)})
+		}
+	}
+
+	models := make([]T, len(items))
+	errs := make([]error, len(items))
+	parallelDo(len(items), func(i int) {
+		errs[i] = r.DecodeModel(items[i].value, &models[i])
+	})
+
+	var decodeErrs []error
+	for i, it := range items {
+		if errs[i] != nil {
+			missing = append(missing, it.key)
+			decodeErrs = append(decodeErrs, fmt.Errorf("key=%s: %w", it.key, errs[i]))
+			continue
+		}
+		result[it.key] = models[i]
+	}
+	r.hook.recordCache(len(result), len(missing))
+	if len(decodeErrs) > 0 {
+		return result, missing, fmt.Errorf("MGetModels[decode]: %w", errors.Join(decodeErrs...))
+	}
+	return result, missing, nil
+}
+
+// MSetModels 批量写入模型，编码方式与 SetModelToCache 一致，所有 key 使用相同的过期时间
+func (r *RedisClient) MSetModels(ctx context.Context, items map[string]interface{}, ttl time.Duration) error {
+	if len(items) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(items))
+	for k := range items {
+		keys = append(keys, k)
+	}
+
+	values := make([][]byte, len(keys))
+	errs := make([]error, len(keys))
+	parallelDo(len(keys), func(i int) {
+		values[i], errs[i] = r.EncodeModel(items[keys[i]])
+	})
+	for i, err := range errs {
+		if err != nil {
+			return fmt.Errorf("MSetModels[encode] key=%s: %w", keys[i], err)
+		}
+	}
+
+	// 集群模式下 pipeline 会按节点拆分命令，SET 幂等可整体重试
+	_, err := r.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			pipe.Set(ctx, key, values[i], ttl)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("MSetModels: %w", err)
+	}
+	return nil
+}
+
+// groupKeysBySlot 集群模式下按 slot 分组，保证每组 MGET 不会跨 slot
+func (r *RedisClient) groupKeysBySlot(keys []string) [][]string {
+	if r.config.Mode != ModeCluster {
+		return [][]string{keys}
+	}
+	index := make(map[int]int)
+	var groups [][]string
+	for _, key := range keys {
+		// 前缀由钩子添加，slot 需按实际发送的 key 计算
+		slot := keySlot(r.config.KeyPrefix + key)
+		i, ok := index[slot]
+		if !ok {
+			i = len(groups)
+			index[slot] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], key)
+	}
+	return groups
+}
+
+// parallelDo 并行执行 fn(0..n-1)
+func parallelDo(n int, fn func(i int)) {
+	if n < parallelCodecThreshold {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+
+	workers := runtime.GOMAXPROCS(0)
+	if workers > n {
+		workers = n
+	}
+	var wg sync.WaitGroup
+	next := make(chan int, n)
+	for i := 0; i < n; i++ {
+		next <- i
+	}
+	close(next)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				fn(i)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// keySlot 计算 key 所在的集群 slot，与 Redis Cluster 规则一致(支持 {hash tag})
+func keySlot(key string) int {
+	if s := strings.IndexByte(key, '{'); s >= 0 {
+		if e := strings.IndexByte(key[s+1:], '}'); e > 0 {
+			key = key[s+1 : s+1+e]
+		}
+	}
+	return int(crc16(key) % 16384)
+}
+
+// crc16 CRC16-CCITT(XMODEM)
+func crc16(s string) uint16 {
+	var crc uint16
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,186 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+type batchModel struct {
+	Name string
+	N    int
+}
+
+// mgetHook 记录 pipeline 中每条 MGET 实际发送的 key
+type mgetHook struct{ groups *[][]string }
+
+func (h mgetHook) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (h mgetHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook { return next }
+
+func (h mgetHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		for _, cmd := range cmds {
+			if cmd.Name() != "mget" {
+				continue
+			}
+			var keys []string
+			for _, arg := range cmd.Args()[1:] {
+				keys = append(keys, fmt.Sprint(arg))
+			}
+			*h.groups = append(*h.groups, keys)
+		}
+		return next(ctx, cmds)
+	}
+}
+
+func TestKeySlot(t *testing.T) {
+	tests := []struct {
+		key  string
+		want int
+	}{
+		{"foo", 12182},
+		{"123456789", 12739},
+		{"{user1000}.following", keySlot("user1000")},
+		{"{user1000}.followers", keySlot("user1000")},
+		{"foo{}{bar}", keySlot("foo{}{bar}")},
+		{"foo{{bar}}zap", keySlot("{bar")},
+	}
+	for _, tt := range tests {
+		if got := keySlot(tt.key); got != tt.want {
+			t.Errorf("keySlot(%q) = %d, want %d", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestGroupKeysBySlot(t *testing.T) {
+	keys := []string{"a", "b", "c", "{tag}1", "{tag}2", "d", "{tag}3"}
+	for _, cfg := range []*Config{
+		{Mode: ModeCluster},
+		{Mode: ModeCluster, KeyPrefix: "svc:"},
+	} {
+		r := &RedisClient{config: cfg}
+		groups := r.groupKeysBySlot(keys)
+		var all []string
+		for _, g := range groups {
+			slot := keySlot(cfg.KeyPrefix + g[0])
+			for _, k := range g {
+				if s := keySlot(cfg.KeyPrefix + k); s != slot {
+					t.Errorf("prefix %q: group %v spans slots %d and %d", cfg.KeyPrefix, g, slot, s)
+				}
+			}
+			all = append(all, g...)
+		}
+		sort.Strings(all)
+		want := append([]string(nil), keys...)
+		sort.Strings(want)
+		if strings.Join(all, ",") != strings.Join(want, ",") {
+			t.Errorf("prefix %q: grouped keys = %v, want %v", cfg.KeyPrefix, all, want)
+		}
+		if len(groups) >= len(keys) {
+			t.Errorf("prefix %q: %d groups for %d keys, hash tagged keys not grouped", cfg.KeyPrefix, len(groups), len(keys))
+		}
+	}
+
+	r := &RedisClient{config: &Config{}}
+	if groups := r.groupKeysBySlot(keys); len(groups) != 1 || len(groups[0]) != len(keys) {
+		t.Errorf("single mode groups = %v, want one group", groups)
+	}
+}
+
+func TestMGetModels(t *testing.T) {
+	s := miniredis.RunT(t)
+	for _, cfg := range []*Config{
+		{Address: s.Addr()},
+		{Mode: ModeCluster, ClusterAddrs: []string{s.Addr()}, KeyPrefix: "cluster:", Codec: "msgpack"},
+	} {
+		t.Run(string(cfg.Mode)+cfg.KeyPrefix, func(t *testing.T) {
+			c, err := NewClient(cfg)
+			if err != nil {
+				t.Fatalf("NewClient: %v", err)
+			}
+			defer c.Close()
+			var groups [][]string
+			c.AddHook(mgetHook{&groups})
+			ctx := context.Background()
+
+			// 超过 parallelCodecThreshold 以覆盖并行编解码
+			items := make(map[string]interface{})
+			var keys []string
+			for i := 0; i < 2*parallelCodecThreshold; i++ {
+				key := fmt.Sprintf("m%d", i)
+				items[key] = batchModel{Name: key, N: i}
+				keys = append(keys, key)
+			}
+			if err := c.MSetModels(ctx, items, time.Minute); err != nil {
+				t.Fatalf("MSetModels: %v", err)
+			}
+			if err := c.SetAbsent(ctx, "absent", time.Minute); err != nil {
+				t.Fatalf("SetAbsent: %v", err)
+			}
+			if ttl := s.TTL(cfg.KeyPrefix + "m0"); ttl != time.Minute {
+				t.Fatalf("TTL = %s, want 1m", ttl)
+			}
+
+			got, missing, err := MGetModels[batchModel](ctx, c, append(keys, "absent", "missing"))
+			if err != nil {
+				t.Fatalf("MGetModels: %v", err)
+			}
+			if len(got) != len(keys) {
+				t.Fatalf("MGetModels returned %d models, want %d", len(got), len(keys))
+			}
+			for i, key := range keys {
+				if got[key] != (batchModel{Name: key, N: i}) {
+					t.Fatalf("model %s = %+v", key, got[key])
+				}
+			}
+			if _, ok := got["absent"]; ok || len(missing) != 1 || missing[0] != "missing" {
+				t.Fatalf("missing = %v, absent present = %v", missing, ok)
+			}
+
+			if cfg.Mode != ModeCluster {
+				if len(groups) != 1 {
+					t.Fatalf("single mode sent %d MGETs, want 1", len(groups))
+				}
+				return
+			}
+			if len(groups) < 2 {
+				t.Fatalf("cluster mode sent %d MGETs, want one per slot", len(groups))
+			}
+			for _, g := range groups {
+				for _, k := range g {
+					if !strings.HasPrefix(k, cfg.KeyPrefix) || keySlot(k) != keySlot(g[0]) {
+						t.Fatalf("MGET %v crosses slots or lacks prefix", g)
+					}
+				}
+			}
+		})
+	}
+}
+
+// 解码失败的 key 计入未命中并返回错误，其余结果正常返回
+func TestMGetModelsDecodeError(t *testing.T) {
+	c, s := newTestClient(t, nil)
+	ctx := context.Background()
+	if err := c.SetModelToCache(ctx, "ok", batchModel{Name: "ok"}, 0); err != nil {
+		t.Fatal(err)
+	}
+	s.Set("bad", "not an envelope")
+
+	got, missing, err := MGetModels[batchModel](ctx, c, []string{"ok", "bad"})
+	if err == nil || !strings.Contains(err.Error(), "key=bad") {
+		t.Fatalf("MGetModels error = %v, want decode error for bad", err)
+	}
+	if got["ok"].Name != "ok" || len(missing) != 1 || missing[0] != "bad" {
+		t.Fatalf("MGetModels = %v, missing %v", got, missing)
+	}
+	if got, missing, err := MGetModels[batchModel](ctx, c, nil); err != nil || len(got) != 0 || missing != nil {
+		t.Fatalf("MGetModels(nil) = %v, %v, %v", got, missing, err)
+	}
+}
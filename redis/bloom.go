@@ -0,0 +1,107 @@
+package redis
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 单个 Redis 字符串最大 512MB，即 2^32 位
+const maxBloomBits = 1 << 32
+
+var (
+	// ARGV[1] 为哈希函数个数 k，其后每 k 个偏移量对应一个元素；返回每个元素是否为新增
+	bloomAddScript = builtinScript("bloom_add.lua")
+
+	// 参数同上；返回每个元素是否可能存在
+	bloomExistsScript = builtinScript("bloom_exists.lua")
+)
+
+// BloomFilter 基于 bitmap 与 Lua 的布隆过滤器，无需 RedisBloom 模块
+// 元素可能误判为存在(概率约为 errorRate)，不会误判为不存在；不支持删除单个元素
+type BloomFilter struct {
+	client *RedisClient
+	key    string
+	bits   uint64
+	hashes int
+}
+
+// NewBloomFilter 按预期元素数量与误判率计算位数组大小与哈希函数个数
+func (r *RedisClient) NewBloomFilter(name string, capacity uint64, errorRate float64) (*BloomFilter, error) {
+	if capacity == 0 {
+		return nil, fmt.Errorf("bloom filter capacity must be positive")
+	}
+	if errorRate <= 0 || errorRate >= 1 {
+		return nil, fmt.Errorf("bloom filter error rate must be in (0, 1)")
+	}
+	// m = -n*ln(p)/(ln2)^2, k = m/n*ln2
+	m := math.Ceil(-float64(capacity) * math.Log(errorRate) / (math.Ln2 * math.Ln2))
+	if m > maxBloomBits {
+		return nil, fmt.Errorf("bloom filter requires %.0f bits, exceeds redis string limit", m)
+	}
+	k := int(math.Round(m / float64(capacity) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	return &BloomFilter{
+		client: r,
+		key:    "bloom:{" + name + "}",
+		bits:   uint64(m),
+		hashes: k,
+	}, nil
+}
+
+// Add 添加元素，返回每个元素是否为新增(false 表示可能已存在)
+func (b *BloomFilter) Add(ctx context.Context, items ...string) ([]bool, error) {
+	return b.run(ctx, bloomAddScript, "Add", items)
+}
+
+// Exists 判断元素是否可能存在
+func (b *BloomFilter) Exists(ctx context.Context, items ...string) ([]bool, error) {
+	return b.run(ctx, bloomExistsScript, "Exists", items)
+}
+
+// Reset 清空过滤器
+func (b *BloomFilter) Reset(ctx context.Context) error {
+	if err := b.client.Del(ctx, b.key).Err(); err != nil {
+		return fmt.Errorf("BloomFilter.Reset key=%s: %w", b.key, err)
+	}
+	return nil
+}
+
+func (b *BloomFilter) run(ctx context.Context, script *redis.Script, op string, items []string) ([]bool, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	args := make([]interface{}, 0, 1+len(items)*b.hashes)
+	args = append(args, b.hashes)
+	for _, item := range items {
+		args = b.appendOffsets(args, item)
+	}
+	vals, err := script.Run(ctx, b.client, []string{b.key}, args...).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("BloomFilter.%s key=%s: %w", op, b.key, err)
+	}
+	result := make([]bool, len(vals))
+	for i, v := range vals {
+		result[i] = v == 1
+	}
+	return result, nil
+}
+
+// appendOffsets 双重哈希 h1 + i*h2 生成 k 个位偏移
+func (b *BloomFilter) appendOffsets(args []interface{}, item string) []interface{} {
+	h := fnv.New128a()
+	_, _ = h.Write([]byte(item))
+	sum := h.Sum(nil)
+	h1 := binary.BigEndian.Uint64(sum[:8])
+	h2 := binary.BigEndian.Uint64(sum[8:]) | 1
+	for i := 0; i < b.hashes; i++ {
+		args = append(args, (h1+uint64(i)*h2)%b.bits)
+	}
+	return args
+}
@@ -0,0 +1,97 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestNewBloomFilter(t *testing.T) {
+	c, _ := newTestClient(t, nil)
+	b, err := c.NewBloomFilter("users", 1000, 0.01)
+	if err != nil {
+		t.Fatalf("NewBloomFilter: %v", err)
+	}
+	// m = -1000*ln(0.01)/(ln2)^2 ≈ 9586, k ≈ 7
+	if b.bits != 9586 || b.hashes != 7 || b.key != "bloom:{users}" {
+		t.Fatalf("bloom filter = bits %d, hashes %d, key %s", b.bits, b.hashes, b.key)
+	}
+
+	for _, tt := range []struct {
+		capacity  uint64
+		errorRate float64
+	}{
+		{0, 0.01},
+		{1000, 0},
+		{1000, 1},
+		{1 << 40, 0.0001},
+	} {
+		if _, err := c.NewBloomFilter("bad", tt.capacity, tt.errorRate); err == nil {
+			t.Errorf("NewBloomFilter(%d, %g) succeeded, want error", tt.capacity, tt.errorRate)
+		}
+	}
+}
+
+func TestBloomFilter(t *testing.T) {
+	c, _ := newTestClient(t, nil)
+	ctx := context.Background()
+	b, err := c.NewBloomFilter("users", 1000, 0.01)
+	if err != nil {
+		t.Fatalf("NewBloomFilter: %v", err)
+	}
+
+	added, err := b.Add(ctx, "a", "b")
+	if err != nil || len(added) != 2 || !added[0] || !added[1] {
+		t.Fatalf("Add = %v, %v, want both new", added, err)
+	}
+	if added, err := b.Add(ctx, "a", "c"); err != nil || added[0] || !added[1] {
+		t.Fatalf("Add = %v, %v, want [false true]", added, err)
+	}
+	if exists, err := b.Exists(ctx); err != nil || exists != nil {
+		t.Fatalf("Exists() = %v, %v", exists, err)
+	}
+
+	items := make([]string, 1000)
+	for i := range items {
+		items[i] = fmt.Sprintf("member-%d", i)
+	}
+	if _, err := b.Add(ctx, items...); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	// 已添加的元素不会误判为不存在
+	exists, err := b.Exists(ctx, items...)
+	if err != nil {
+		t.Fatalf("Exists: %v", err)
+	}
+	for i, ok := range exists {
+		if !ok {
+			t.Fatalf("%s reported absent after Add", items[i])
+		}
+	}
+
+	// 未添加的元素误判率应接近 errorRate
+	others := make([]string, 1000)
+	for i := range others {
+		others[i] = fmt.Sprintf("other-%d", i)
+	}
+	exists, err = b.Exists(ctx, others...)
+	if err != nil {
+		t.Fatalf("Exists: %v", err)
+	}
+	var positives int
+	for _, ok := range exists {
+		if ok {
+			positives++
+		}
+	}
+	if positives > 30 {
+		t.Fatalf("false positives = %d/1000, want about 1%%", positives)
+	}
+
+	if err := b.Reset(ctx); err != nil {
+		t.Fatalf("Reset: %v", err)
+	}
+	if exists, err := b.Exists(ctx, "a"); err != nil || exists[0] {
+		t.Fatalf("Exists after Reset = %v, %v", exists, err)
+	}
+}
@@ -0,0 +1,307 @@
+package redis
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+// 缓存标志位 = 序列化器ID*10 + 压缩器ID，与 CacheFormatXXX 常量保持一致
+const (
+	// CodecRaw 原始字节
+	CodecRaw uint8 = 0
+	// CodecJSON json
+	CodecJSON uint8 = 1
+	// CodecMsgpack msgpack
+	CodecMsgpack uint8 = 2
+	// CodecProtobuf protobuf，模型需为 protobuf 消息(T、*T 或 **T)
+	CodecProtobuf uint8 = 3
+
+	// CompressNone 不压缩
+	CompressNone uint8 = 0
+	// CompressGzip gzip
+	CompressGzip uint8 = 1
+	// CompressZstd zstd
+	CompressZstd uint8 = 2
+	// CompressSnappy snappy
+	CompressSnappy uint8 = 3
+
+	// 标志位为 uint8，codec*10+compressor 不能超过 255
+	maxCodecID      = 24
+	maxCompressorID = 9
+)
+
+// Codec 序列化器
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// Compressor 压缩器
+type Compressor interface {
+	Compress(data []byte) ([]byte, error)
+	Decompress(data []byte) ([]byte, error)
+}
+
+type registry struct {
+	mu              sync.RWMutex
+	codecs          map[uint8]Codec
+	compressors     map[uint8]Compressor
+	codecNames      map[string]uint8
+	compressorNames map[string]uint8
+}
+
+var defaultRegistry = &registry{
+	codecs:          make(map[uint8]Codec),
+	compressors:     make(map[uint8]Compressor),
+	codecNames:      make(map[string]uint8),
+	compressorNames: make(map[string]uint8),
+}
+
+func init() {
+	RegisterCodec(CodecRaw, "raw", rawCodec{})
+	RegisterCodec(CodecJSON, "json", jsonCodec{})
+	RegisterCodec(CodecMsgpack, "msgpack", msgpackCodec{})
+	RegisterCodec(CodecProtobuf, "protobuf", protobufCodec{})
+
+	RegisterCompressor(CompressNone, "none", nil)
+	RegisterCompressor(CompressGzip, "gzip", gzipCompressor{})
+	RegisterCompressor(CompressZstd, "zstd", newZstdCompressor())
+	RegisterCompressor(CompressSnappy, "snappy", snappyCompressor{})
+}
+
+// RegisterCodec 注册序列化器，id 取值 0-24，重复注册会覆盖
+func RegisterCodec(id uint8, name string, c Codec) {
+	if id > maxCodecID {
+		panic(fmt.Sprintf("redis: codec id %d out of range", id))
+	}
+	defaultRegistry.mu.Lock()
+	defer defaultRegistry.mu.Unlock()
+	defaultRegistry.codecs[id] = c
+	defaultRegistry.codecNames[name] = id
+}
+
+// RegisterCompressor 注册压缩器，id 取值 0-9，重复注册会覆盖
+func RegisterCompressor(id uint8, name string, c Compressor) {
+	if id > maxCompressorID {
+		panic(fmt.Sprintf("redis: compressor id %d out of range", id))
+	}
+	defaultRegistry.mu.Lock()
+	defer defaultRegistry.mu.Unlock()
+	defaultRegistry.compressors[id] = c
+	defaultRegistry.compressorNames[name] = id
+}
+
+func lookupCodecID(name string) (uint8, error) {
+	defaultRegistry.mu.RLock()
+	defer defaultRegistry.mu.RUnlock()
+	id, ok := defaultRegistry.codecNames[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown codec: %s", name)
+	}
+	return id, nil
+}
+
+func lookupCompressorID(name string) (uint8, error) {
+	defaultRegistry.mu.RLock()
+	defer defaultRegistry.mu.RUnlock()
+	id, ok := defaultRegistry.compressorNames[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown compressor: %s", name)
+	}
+	return id, nil
+}
+
+// cacheFlag 组合标志位
+func cacheFlag(codec, compressor uint8) uint8 {
+	return codec*10 + compressor
+}
+
+// encodeModel 序列化模型，超过阈值时压缩，返回实际使用的标志位
+func encodeModel(model interface{}, codecID, compressorID uint8, minSize int) (uint8, []byte, error) {
+	defaultRegistry.mu.RLock()
+	codec := defaultRegistry.codecs[codecID]
+	compressor := defaultRegistry.compressors[compressorID]
+	defaultRegistry.mu.RUnlock()
+	if codec == nil {
+		return 0, nil, fmt.Errorf("codec %d not registered", codecID)
+	}
+
+	data, err := codec.Marshal(model)
+	if err != nil {
+		return 0, nil, err
+	}
+	// 动态阈值判断
+	if compressor == nil || len(data) <= minSize {
+		return cacheFlag(codecID, CompressNone), data, nil
+	}
+	compressed, err := compressor.Compress(data)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(compressed) >= len(data) {
+		return cacheFlag(codecID, CompressNone), data, nil // 压缩后反而更大，放弃压缩
+	}
+	return cacheFlag(codecID, compressorID), compressed, nil
+}
+
+// decodeModel 按标志位解压并反序列化
+func decodeModel(flag uint8, data []byte, model interface{}) error {
+	codecID, compressorID := flag/10, flag%10
+
+	defaultRegistry.mu.RLock()
+	codec := defaultRegistry.codecs[codecID]
+	compressor, ok := defaultRegistry.compressors[compressorID]
+	defaultRegistry.mu.RUnlock()
+	if codec == nil || !ok {
+		return fmt.Errorf("invalid flag %d", flag)
+	}
+
+	if compressor != nil {
+		var err error
+		if data, err = compressor.Decompress(data); err != nil {
+			return fmt.Errorf("decompress: %w", err)
+		}
+	}
+	return codec.Unmarshal(data, model)
+}
+
+// rawCodec 原始数据，支持 []byte 与 string
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	switch val := v.(type) {
+	case []byte:
+		return val, nil
+	case string:
+		return []byte(val), nil
+	default:
+		return nil, fmt.Errorf("raw codec: unsupported type %T", v)
+	}
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	switch val := v.(type) {
+	case *[]byte:
+		*val = append((*val)[:0], data...)
+	case *string:
+		*val = string(data)
+	default:
+		return fmt.Errorf("raw codec: unsupported type %T", v)
+	}
+	return nil
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error) {
+	return msgpack.Marshal(v)
+}
+
+func (msgpackCodec) Unmarshal(data []byte, v interface{}) error {
+	return msgpack.Unmarshal(data, v)
+}
+
+// protobufCodec 除 proto.Message 外，还支持泛型接口中常见的消息值(T)与指向消息指针的指针(**T)
+type protobufCodec struct{}
+
+func (protobufCodec) Marshal(v interface{}) ([]byte, error) {
+	msg, ok := protoMessage(v, false)
+	if !ok {
+		return nil, fmt.Errorf("protobuf codec: %T does not implement proto.Message", v)
+	}
+	return proto.Marshal(msg)
+}
+
+func (protobufCodec) Unmarshal(data []byte, v interface{}) error {
+	msg, ok := protoMessage(v, true)
+	if !ok {
+		return fmt.Errorf("protobuf codec: %T does not implement proto.Message", v)
+	}
+	return proto.Unmarshal(data, msg)
+}
+
+var protoMessageType = reflect.TypeOf((*proto.Message)(nil)).Elem()
+
+// protoMessage 取出 v 对应的 proto.Message
+// v 为消息值时复制到新分配的指针中；v 为 **T 且 *T 为 nil 时，alloc 为 true 则分配新消息
+func protoMessage(v interface{}, alloc bool) (proto.Message, bool) {
+	if msg, ok := v.(proto.Message); ok {
+		return msg, true
+	}
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return nil, false
+	}
+	switch {
+	case rv.Kind() != reflect.Ptr:
+		// T: 消息值，方法定义在 *T 上
+		if !reflect.PointerTo(rv.Type()).Implements(protoMessageType) {
+			return nil, false
+		}
+		p := reflect.New(rv.Type())
+		p.Elem().Set(rv)
+		return p.Interface().(proto.Message), true
+	case rv.Type().Elem().Kind() == reflect.Ptr && rv.Type().Elem().Implements(protoMessageType):
+		// **T
+		if rv.IsNil() {
+			return nil, false
+		}
+		elem := rv.Elem()
+		if elem.IsNil() {
+			if !alloc {
+				return elem.Interface().(proto.Message), true
+			}
+			elem.Set(reflect.New(elem.Type().Elem()))
+		}
+		return elem.Interface().(proto.Message), true
+	default:
+		return nil, false
+	}
+}
+
+// zstdCompressor EncodeAll/DecodeAll 可并发调用，共用一个编解码器
+type zstdCompressor struct {
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+}
+
+func newZstdCompressor() *zstdCompressor {
+	encoder, _ := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedFastest))
+	decoder, _ := zstd.NewReader(nil, zstd.WithDecoderConcurrency(0))
+	return &zstdCompressor{encoder: encoder, decoder: decoder}
+}
+
+func (c *zstdCompressor) Compress(data []byte) ([]byte, error) {
+	return c.encoder.EncodeAll(data, nil), nil
+}
+
+func (c *zstdCompressor) Decompress(data []byte) ([]byte, error) {
+	return c.decoder.DecodeAll(data, nil)
+}
+
+type snappyCompressor struct{}
+
+func (snappyCompressor) Compress(data []byte) ([]byte, error) {
+	return snappy.Encode(nil, data), nil
+}
+
+func (snappyCompressor) Decompress(data []byte) ([]byte, error) {
+	return snappy.Decode(nil, data)
+}
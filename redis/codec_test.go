@@ -0,0 +1,124 @@
+package redis
+
+import (
+	"bytes"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// roundTrip 按泛型接口的用法编码 v 并解码到 &out
+func roundTrip[T any](t *testing.T, v T, compressor uint8) T {
+	t.Helper()
+	flag, data, err := encodeModel(v, CodecProtobuf, compressor, 0)
+	if err != nil {
+		t.Fatalf("encodeModel(%T): %v", v, err)
+	}
+	var out T
+	if err := decodeModel(flag, data, &out); err != nil {
+		t.Fatalf("decodeModel(%T): %v", &out, err)
+	}
+	return out
+}
+
+func TestProtobufCodecRoundTrip(t *testing.T) {
+	for _, compressor := range []uint8{CompressNone, CompressZstd} {
+		// T=*pb.Msg，解码目标为 **pb.Msg
+		ptr := roundTrip(t, wrapperspb.String("pointer"), compressor)
+		if ptr.GetValue() != "pointer" {
+			t.Errorf("*T round trip = %q, want pointer", ptr.GetValue())
+		}
+
+		// T=pb.Msg，编码时传入消息值
+		val := roundTrip(t, *wrapperspb.Int64(42), compressor)
+		if val.GetValue() != 42 {
+			t.Errorf("T round trip = %d, want 42", val.GetValue())
+		}
+	}
+}
+
+func TestProtobufCodecProtoMessage(t *testing.T) {
+	msg := wrapperspb.Bool(true)
+	data, err := protobufCodec{}.Marshal(msg)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	out := &wrapperspb.BoolValue{}
+	if err := (protobufCodec{}).Unmarshal(data, out); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if !proto.Equal(msg, out) {
+		t.Errorf("got %v, want %v", out, msg)
+	}
+}
+
+func TestProtobufCodecRejectsNonMessage(t *testing.T) {
+	type plain struct{ A int }
+	if _, err := (protobufCodec{}).Marshal(plain{A: 1}); err == nil {
+		t.Error("Marshal(plain) succeeded, want error")
+	}
+	var p *plain
+	if err := (protobufCodec{}).Unmarshal(nil, &p); err == nil {
+		t.Error("Unmarshal(**plain) succeeded, want error")
+	}
+}
+
+type boundaryCodec struct{ rawCodec }
+
+// unregister 测试结束后移除注册项，避免影响其他测试
+func unregister(t *testing.T, codecID, compressorID uint8) {
+	t.Cleanup(func() {
+		defaultRegistry.mu.Lock()
+		defer defaultRegistry.mu.Unlock()
+		delete(defaultRegistry.codecs, codecID)
+		delete(defaultRegistry.compressors, compressorID)
+		for name, id := range defaultRegistry.codecNames {
+			if id == codecID {
+				delete(defaultRegistry.codecNames, name)
+			}
+		}
+		for name, id := range defaultRegistry.compressorNames {
+			if id == compressorID {
+				delete(defaultRegistry.compressorNames, name)
+			}
+		}
+	})
+}
+
+func TestRegisterIDBoundary(t *testing.T) {
+	unregister(t, maxCodecID, maxCompressorID)
+	RegisterCodec(maxCodecID, "test-max", boundaryCodec{})
+	RegisterCompressor(maxCompressorID, "test-max", snappyCompressor{})
+
+	// 最大 id 组合的标志位不溢出，能按原 codec 与压缩器解码
+	data := bytes.Repeat([]byte("boundary"), 64)
+	flag, encoded, err := encodeModel(data, maxCodecID, maxCompressorID, 0)
+	if err != nil {
+		t.Fatalf("encodeModel: %v", err)
+	}
+	if flag != 249 || flag/10 != maxCodecID || flag%10 != maxCompressorID {
+		t.Fatalf("flag = %d, want 249", flag)
+	}
+	var out []byte
+	if err := decodeModel(flag, encoded, &out); err != nil || !bytes.Equal(out, data) {
+		t.Fatalf("decodeModel = %d bytes, %v", len(out), err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		fn   func()
+	}{
+		{"codec", func() { RegisterCodec(maxCodecID+1, "overflow", rawCodec{}) }},
+		{"compressor", func() { RegisterCompressor(maxCompressorID+1, "overflow", nil) }},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("register %s id out of range did not panic", tt.name)
+				}
+			}()
+			tt.fn()
+		}()
+	}
+}
@@ -3,7 +3,6 @@ package redis
 import (
 	"bytes"
 	"compress/gzip"
-	"encoding/json"
 	"io"
 	"sync"
 )
@@ -21,56 +20,44 @@ var (
 	}
 )
 
-func toGzipJSON(obj interface{}, gzipMinSize int) (gziped bool, data []byte, err error) {
-	bs, err := json.Marshal(obj)
-	if err != nil {
-		return false, nil, err
-	}
-
-	// 动态阈值判断
-	if len(bs) <= gzipMinSize {
-		return false, bs, nil
-	}
+type gzipCompressor struct{}
 
+func (gzipCompressor) Compress(data []byte) ([]byte, error) {
 	// 从内存池获取资源
 	buf := bufferPool.Get().(*bytes.Buffer)
-	defer bufferPool.Put(buf)
 	buf.Reset()
+	defer func() {
+		buf.Reset()
+		bufferPool.Put(buf)
+	}()
 
 	// 从池中获取writer
 	gzWriter := gzipWriterPool.Get().(*gzip.Writer)
 	defer gzipWriterPool.Put(gzWriter)
 	gzWriter.Reset(buf)
 
-	if _, err = gzWriter.Write(bs); err != nil {
-		return false, nil, err
+	if _, err := gzWriter.Write(data); err != nil {
+		return nil, err
 	}
-	if err = gzWriter.Close(); err != nil {
-		return false, nil, err
+	if err := gzWriter.Close(); err != nil {
+		return nil, err
 	}
-	compressedData := buf.Bytes()
-	if len(compressedData) >= len(bs) {
-		return false, bs, nil // 压缩后反而更大，放弃压缩
-	}
-	return true, buf.Bytes(), nil
+	// buf 会归还到池中，需拷贝结果
+	return append([]byte(nil), buf.Bytes()...), nil
 }
 
 // 解压逻辑
-func fromGzipJSON(data []byte, obj interface{}) (err error) {
-	// 使用更高效的内存分配方式
-	buf := bytes.NewBuffer(data)
-
-	gzReader, err := gzip.NewReader(buf)
+func (gzipCompressor) Decompress(data []byte) ([]byte, error) {
+	gzReader, err := gzip.NewReader(bytes.NewReader(data))
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer gzReader.Close()
 
-	// 预分配内存
-	bs := make([]byte, 0, len(data)*3) // 根据压缩比预估
-	if bs, err = io.ReadAll(gzReader); err != nil {
-		return err
+	// 根据压缩比预分配内存
+	buf := bytes.NewBuffer(make([]byte, 0, len(data)*3))
+	if _, err := io.Copy(buf, gzReader); err != nil {
+		return nil, err
 	}
-
-	return json.Unmarshal(bs, obj)
+	return buf.Bytes(), nil
 }
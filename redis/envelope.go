@@ -0,0 +1,69 @@
+package redis
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// 二进制缓存格式: magic(1) | version(1) | flag(1) | payload
+// 相比 JSON 包装的 modelCacheItem，payload 无需 base64 编码(约节省 33% 空间)，读取时也只需解码一次
+const (
+	envelopeMagic      byte = 0xCA
+	envelopeVersion    byte = 1
+	envelopeHeaderSize      = 3
+)
+
+var errInvalidEnvelope = errors.New("invalid cache envelope")
+
+// ErrAbsent 缓存中记录的是"已知不存在"标记(由 SetAbsent 写入)
+var ErrAbsent = errors.New("redis: cached as absent")
+
+// absentValue 负缓存标记: magic + 版本0，不携带数据
+var absentValue = []byte{envelopeMagic, 0}
+
+// IsAbsent 判断缓存值是否为 SetAbsent 写入的负缓存标记
+func IsAbsent(value []byte) bool {
+	return len(value) == len(absentValue) && value[0] == absentValue[0] && value[1] == absentValue[1]
+}
+
+// marshalEnvelope 拼接头部与数据
+func marshalEnvelope(flag uint8, data []byte) []byte {
+	buf := make([]byte, envelopeHeaderSize+len(data))
+	buf[0] = envelopeMagic
+	buf[1] = envelopeVersion
+	buf[2] = flag
+	copy(buf[envelopeHeaderSize:], data)
+	return buf
+}
+
+// unmarshalEnvelope 解析缓存值，兼容历史 JSON 格式({"Flag":..,"Data":..})
+func unmarshalEnvelope(value []byte) (uint8, []byte, error) {
+	if len(value) == 0 {
+		return 0, nil, errInvalidEnvelope
+	}
+	switch value[0] {
+	case envelopeMagic:
+		if IsAbsent(value) {
+			return 0, nil, ErrAbsent
+		}
+		if len(value) < envelopeHeaderSize {
+			return 0, nil, errInvalidEnvelope
+		}
+		if value[1] != envelopeVersion {
+			return 0, nil, fmt.Errorf("unsupported cache envelope version %d", value[1])
+		}
+		return value[2], value[envelopeHeaderSize:], nil
+	case '{':
+		var item modelCacheItem
+		if err := json.Unmarshal(value, &item); err != nil {
+			return 0, nil, err
+		}
+		if item.Flag > 255 {
+			return 0, nil, fmt.Errorf("invalid flag %d", item.Flag)
+		}
+		return uint8(item.Flag), item.Data, nil
+	default:
+		return 0, nil, errInvalidEnvelope
+	}
+}
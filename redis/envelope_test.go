@@ -0,0 +1,138 @@
+package redis
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+type envelopeModel struct {
+	ID    int64    `json:"id" msgpack:"id"`
+	Name  string   `json:"name" msgpack:"name"`
+	Tags  []string `json:"tags" msgpack:"tags"`
+	Bytes []byte   `json:"bytes" msgpack:"bytes"`
+}
+
+func newEnvelopeModel() envelopeModel {
+	b := make([]byte, 512)
+	rand.New(rand.NewSource(1)).Read(b)
+	return envelopeModel{ID: 42, Name: "envelope", Tags: []string{"a", "b", "c"}, Bytes: b}
+}
+
+func TestUnmarshalEnvelopeLegacyJSON(t *testing.T) {
+	want := newEnvelopeModel()
+	for _, tt := range []struct {
+		codec, compressor uint8
+	}{
+		{CodecJSON, CompressNone},
+		{CodecJSON, CompressGzip},
+		{CodecMsgpack, CompressZstd},
+		{CodecMsgpack, CompressSnappy},
+	} {
+		flag, data, err := encodeModel(want, tt.codec, tt.compressor, 0)
+		if err != nil {
+			t.Fatalf("encodeModel: %v", err)
+		}
+		legacy, err := json.Marshal(modelCacheItem{Flag: uint32(flag), Data: data})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.HasPrefix(legacy, []byte(`{"Flag":`)) {
+			t.Fatalf("legacy value = %s", legacy)
+		}
+
+		gotFlag, gotData, err := unmarshalEnvelope(legacy)
+		if err != nil {
+			t.Fatalf("unmarshalEnvelope(flag=%d): %v", flag, err)
+		}
+		if gotFlag != flag || !bytes.Equal(gotData, data) {
+			t.Fatalf("unmarshalEnvelope(flag=%d) = %d, %d bytes", flag, gotFlag, len(gotData))
+		}
+		var got envelopeModel
+		if err := decodeModel(gotFlag, gotData, &got); err != nil {
+			t.Fatalf("decodeModel(flag=%d): %v", flag, err)
+		}
+		if got.ID != want.ID || got.Name != want.Name || !bytes.Equal(got.Bytes, want.Bytes) {
+			t.Errorf("flag=%d decoded %+v", flag, got)
+		}
+	}
+}
+
+func TestUnmarshalEnvelope(t *testing.T) {
+	value := marshalEnvelope(cacheFlag(CodecJSON, CompressNone), []byte(`{"id":1}`))
+	flag, data, err := unmarshalEnvelope(value)
+	if err != nil || flag != cacheFlag(CodecJSON, CompressNone) || string(data) != `{"id":1}` {
+		t.Fatalf("unmarshalEnvelope = %d, %q, %v", flag, data, err)
+	}
+	if _, _, err := unmarshalEnvelope(absentValue); !errors.Is(err, ErrAbsent) {
+		t.Errorf("absent value error = %v, want ErrAbsent", err)
+	}
+	for _, value := range [][]byte{nil, {envelopeMagic}, {envelopeMagic, 9, 0}, []byte("plain")} {
+		if _, _, err := unmarshalEnvelope(value); err == nil {
+			t.Errorf("unmarshalEnvelope(%q) succeeded, want error", value)
+		}
+	}
+}
+
+func benchmarkPayload(b *testing.B) (uint8, []byte) {
+	b.Helper()
+	flag, data, err := encodeModel(newEnvelopeModel(), CodecMsgpack, CompressNone, 0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return flag, data
+}
+
+// BenchmarkEnvelopeLegacyJSON 历史格式: JSON 包装，payload 经 base64 编码
+func BenchmarkEnvelopeLegacyJSON(b *testing.B) {
+	flag, data := benchmarkPayload(b)
+	value, _ := json.Marshal(modelCacheItem{Flag: uint32(flag), Data: data})
+
+	b.Run("marshal", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			if _, err := json.Marshal(modelCacheItem{Flag: uint32(flag), Data: data}); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.ReportMetric(float64(len(value)), "value_bytes")
+	})
+	b.Run("unmarshal", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			if _, _, err := unmarshalEnvelope(value); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.ReportMetric(float64(len(value)), "value_bytes")
+	})
+}
+
+// BenchmarkEnvelopeBinary 二进制格式: 3字节头部 + 原始 payload
+func BenchmarkEnvelopeBinary(b *testing.B) {
+	flag, data := benchmarkPayload(b)
+	value := marshalEnvelope(flag, data)
+
+	b.Run("marshal", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			_ = marshalEnvelope(flag, data)
+		}
+		b.ReportMetric(float64(len(value)), "value_bytes")
+	})
+	b.Run("unmarshal", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			if _, _, err := unmarshalEnvelope(value); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.ReportMetric(float64(len(value)), "value_bytes")
+	})
+}
@@ -0,0 +1,404 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/tiamxu/kit/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	defaultSlowThreshold  = 100 * time.Millisecond
+	defaultReportInterval = time.Minute
+	tracerName            = "github.com/tiamxu/kit/redis"
+)
+
+// 延迟直方图的桶上界，最后一个桶收集超过 1s 的命令
+var latencyBuckets = [...]time.Duration{
+	time.Millisecond,
+	2 * time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+}
+
+// StatsConfig 客户端观测配置
+type StatsConfig struct {
+	// SlowThreshold 慢命令阈值，超过时输出 warn 日志，默认100ms
+	SlowThreshold time.Duration `yaml:"slow_threshold" json:"slow_threshold"`
+	// ReportInterval 周期性输出命令统计与连接池状态的间隔，默认1m
+	ReportInterval time.Duration `yaml:"report_interval" json:"report_interval"`
+}
+
+// CommandStats 单个命令在统计周期内的数据
+type CommandStats struct {
+	Count  int64
+	Errors int64
+	// Total 总耗时
+	Total time.Duration
+	// Buckets 与 latencyBuckets 对应的计数，最后一个元素为超过 1s 的次数
+	Buckets []int64
+}
+
+// Quantile 按直方图估算分位数延迟(返回所在桶的上界)
+func (s *CommandStats) Quantile(q float64) time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	target := int64(float64(s.Count)*q + 0.5)
+	if target < 1 {
+		target = 1
+	}
+	var acc int64
+	for i, n := range s.Buckets {
+		acc += n
+		if acc >= target {
+			if i < len(latencyBuckets) {
+				return latencyBuckets[i]
+			}
+			break
+		}
+	}
+	return latencyBuckets[len(latencyBuckets)-1]
+}
+
+type commandStats struct {
+	count   atomic.Int64
+	errors  atomic.Int64
+	total   atomic.Int64
+	buckets [len(latencyBuckets) + 1]atomic.Int64
+}
+
+func (s *commandStats) observe(d time.Duration, failed bool) {
+	s.count.Add(1)
+	s.total.Add(int64(d))
+	if failed {
+		s.errors.Add(1)
+	}
+	i := sort.Search(len(latencyBuckets), func(i int) bool { return d <= latencyBuckets[i] })
+	s.buckets[i].Add(1)
+}
+
+// swap 取出当前周期数据并清零
+func (s *commandStats) swap() CommandStats {
+	out := CommandStats{
+		Count:   s.count.Swap(0),
+		Errors:  s.errors.Swap(0),
+		Total:   time.Duration(s.total.Swap(0)),
+		Buckets: make([]int64, len(s.buckets)),
+	}
+	for i := range s.buckets {
+		out.Buckets[i] = s.buckets[i].Swap(0)
+	}
+	return out
+}
+
+// Hook 客户端观测钩子，记录命令延迟直方图、错误与慢命令，可选生成 OpenTelemetry span
+type Hook struct {
+	slowThreshold time.Duration
+	tracer        trace.Tracer
+
+	commands sync.Map // name -> *commandStats
+	hits     atomic.Int64
+	misses   atomic.Int64
+}
+
+// NewHook 创建观测钩子，tp 为空时不生成 span
+func NewHook(cfg StatsConfig, tp trace.TracerProvider) *Hook {
+	if cfg.SlowThreshold <= 0 {
+		cfg.SlowThreshold = defaultSlowThreshold
+	}
+	h := &Hook{slowThreshold: cfg.SlowThreshold}
+	if tp != nil {
+		h.tracer = tp.Tracer(tracerName)
+	}
+	return h
+}
+
+// DialHook 实现 redis.Hook
+func (h *Hook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		start := time.Now()
+		conn, err := next(ctx, network, addr)
+		h.observe("dial", time.Since(start), err)
+		if err != nil {
+			log.GetLogger().Warnf("redis dial %s failed: %v", addr, err)
+		}
+		return conn, err
+	}
+}
+
+// ProcessHook 实现 redis.Hook
+func (h *Hook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		name := cmd.Name()
+		ctx, span := h.startSpan(ctx, name, 1)
+		start := time.Now()
+		err := next(ctx, cmd)
+		elapsed := activeDuration(time.Since(start), cmd)
+
+		h.observe(name, elapsed, cmd.Err())
+		h.logSlow(name, firstKey(cmd), 1, elapsed)
+		h.endSpan(span, cmd.Err())
+		return err
+	}
+}
+
+// ProcessPipelineHook 实现 redis.Hook，pipeline 按一次调用统计
+func (h *Hook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		ctx, span := h.startSpan(ctx, "pipeline", len(cmds))
+		start := time.Now()
+		err := next(ctx, cmds)
+		elapsed := activeDuration(time.Since(start), cmds...)
+
+		h.observe("pipeline", elapsed, err)
+		key := ""
+		if len(cmds) > 0 {
+			key = cmds[0].Name() + " " + firstKey(cmds[0])
+		}
+		h.logSlow("pipeline", key, len(cmds), elapsed)
+		h.endSpan(span, err)
+		return err
+	}
+}
+
+// CacheHits 返回并清零统计周期内 GetCacheToModel / MGetModels 的命中与未命中次数
+func (h *Hook) CacheHits() (hits, misses int64) {
+	return h.hits.Swap(0), h.misses.Swap(0)
+}
+
+// Commands 返回并清零统计周期内各命令的统计数据
+func (h *Hook) Commands() map[string]CommandStats {
+	out := make(map[string]CommandStats)
+	h.commands.Range(func(k, v interface{}) bool {
+		if s := v.(*commandStats).swap(); s.Count > 0 {
+			out[k.(string)] = s
+		}
+		return true
+	})
+	return out
+}
+
+func (h *Hook) recordCache(hits, misses int) {
+	if h == nil {
+		return
+	}
+	h.hits.Add(int64(hits))
+	h.misses.Add(int64(misses))
+}
+
+func (h *Hook) observe(name string, d time.Duration, err error) {
+	v, ok := h.commands.Load(name)
+	if !ok {
+		v, _ = h.commands.LoadOrStore(name, &commandStats{})
+	}
+	v.(*commandStats).observe(d, isCommandError(err))
+}
+
+func (h *Hook) logSlow(name, key string, size int, d time.Duration) {
+	if d < h.slowThreshold {
+		return
+	}
+	fields := log.Fields{
+		"cmd":      name,
+		"key":      key,
+		"duration": d.String(),
+	}
+	if size > 1 {
+		fields["size"] = size
+	}
+	log.GetLogger().WithFields(fields).Warn("redis slow command")
+}
+
+func (h *Hook) startSpan(ctx context.Context, name string, size int) (context.Context, trace.Span) {
+	if h.tracer == nil {
+		return ctx, nil
+	}
+	ctx, span := h.tracer.Start(ctx, "redis."+name, trace.WithSpanKind(trace.SpanKindClient))
+	span.SetAttributes(
+		attribute.String("db.system", "redis"),
+		attribute.String("db.operation", name),
+	)
+	if size > 1 {
+		span.SetAttributes(attribute.Int("db.redis.num_cmd", size))
+	}
+	return ctx, span
+}
+
+func (h *Hook) endSpan(span trace.Span, err error) {
+	if span == nil {
+		return
+	}
+	if isCommandError(err) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// report 周期性输出命令统计、命中率与连接池状态，直到 stop 关闭
+func (r *RedisClient) report(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		pool := r.PoolStats()
+		hits, misses := r.hook.CacheHits()
+		fields := log.Fields{
+			"interval":         interval.String(),
+			"cache_hits":       hits,
+			"cache_misses":     misses,
+			"pool_hits":        pool.Hits,
+			"pool_misses":      pool.Misses,
+			"pool_timeouts":    pool.Timeouts,
+			"pool_total_conns": pool.TotalConns,
+			"pool_idle_conns":  pool.IdleConns,
+			"pool_stale_conns": pool.StaleConns,
+			"pool_size":        r.config.PoolSize,
+		}
+		if hits+misses > 0 {
+			fields["cache_hit_ratio"] = float64(hits) / float64(hits+misses)
+		}
+		log.GetLogger().WithFields(fields).Info("redis client stats")
+
+		for name, s := range r.hook.Commands() {
+			log.GetLogger().WithFields(log.Fields{
+				"cmd":      name,
+				"count":    s.Count,
+				"errors":   s.Errors,
+				"avg":      (s.Total / time.Duration(s.Count)).String(),
+				"p50":      s.Quantile(0.5).String(),
+				"p99":      s.Quantile(0.99).String(),
+				"buckets":  formatBuckets(s.Buckets),
+				"interval": interval.String(),
+			}).Info("redis command stats")
+		}
+	}
+}
+
+// isCommandError redis.Nil 表示 key 不存在，不计为错误
+func isCommandError(err error) bool {
+	return err != nil && !errors.Is(err, redis.Nil)
+}
+
+// activeDuration 阻塞命令(BLPOP、XREADGROUP BLOCK 等)的耗时扣除阻塞等待时间，
+// 无限阻塞时记为0，避免空闲轮询被统计为慢命令
+func activeDuration(elapsed time.Duration, cmds ...redis.Cmder) time.Duration {
+	var block time.Duration
+	for _, cmd := range cmds {
+		d, ok := blockTimeout(cmd)
+		if !ok {
+			continue
+		}
+		if d == 0 {
+			return 0
+		}
+		block = max(block, d)
+	}
+	return max(elapsed-block, 0)
+}
+
+// blockTimeout 返回阻塞命令的超时参数，0 表示无限阻塞；非阻塞命令返回 false
+func blockTimeout(cmd redis.Cmder) (time.Duration, bool) {
+	args := cmd.Args()
+	var seconds interface{}
+	switch cmd.Name() {
+	case "blpop", "brpop", "bzpopmin", "bzpopmax", "brpoplpush", "blmove":
+		// 超时为最后一个参数，单位秒
+		if len(args) < 3 {
+			return 0, false
+		}
+		seconds = args[len(args)-1]
+	case "blmpop", "bzmpop":
+		// 超时为第一个参数，单位秒
+		if len(args) < 2 {
+			return 0, false
+		}
+		seconds = args[1]
+	case "xread", "xreadgroup":
+		// BLOCK milliseconds
+		for i := 1; i+1 < len(args); i++ {
+			if s, ok := args[i].(string); ok && strings.EqualFold(s, "block") {
+				ms, ok := argFloat(args[i+1])
+				if !ok || ms < 0 {
+					return 0, false
+				}
+				return time.Duration(ms * float64(time.Millisecond)), true
+			}
+		}
+		return 0, false
+	default:
+		return 0, false
+	}
+	sec, ok := argFloat(seconds)
+	if !ok || sec < 0 {
+		return 0, false
+	}
+	return time.Duration(sec * float64(time.Second)), true
+}
+
+func argFloat(v interface{}) (float64, bool) {
+	switch val := v.(type) {
+	case int:
+		return float64(val), true
+	case int64:
+		return float64(val), true
+	case float64:
+		return val, true
+	case string:
+		f, err := strconv.ParseFloat(val, 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// firstKey 返回命令的第一个参数(通常为 key)，仅用于日志
+func firstKey(cmd redis.Cmder) string {
+	args := cmd.Args()
+	if len(args) < 2 {
+		return ""
+	}
+	return fmt.Sprint(args[1])
+}
+
+func formatBuckets(buckets []int64) string {
+	var b strings.Builder
+	for i, n := range buckets {
+		if n == 0 {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		if i < len(latencyBuckets) {
+			fmt.Fprintf(&b, "le_%s=%d", latencyBuckets[i], n)
+		} else {
+			fmt.Fprintf(&b, "gt_%s=%d", latencyBuckets[len(latencyBuckets)-1], n)
+		}
+	}
+	return b.String()
+}
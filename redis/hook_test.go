@@ -0,0 +1,74 @@
+package redis
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/tiamxu/kit/log"
+)
+
+func TestBlockTimeout(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		cmd  redis.Cmder
+		want time.Duration
+		ok   bool
+	}{
+		{redis.NewStringSliceCmd(ctx, "blpop", "q", 1.5), 1500 * time.Millisecond, true},
+		{redis.NewStringSliceCmd(ctx, "brpop", "a", "b", int64(2)), 2 * time.Second, true},
+		{redis.NewZWithKeyCmd(ctx, "bzpopmin", "z", 0), 0, true},
+		{redis.NewKeyValuesCmd(ctx, "blmpop", 0.25, 1, "q", "left"), 250 * time.Millisecond, true},
+		{redis.NewXStreamSliceCmd(ctx, "xreadgroup", "group", "g", "c", "block", int64(5000), "streams", "s", ">"), 5 * time.Second, true},
+		{redis.NewXStreamSliceCmd(ctx, "xread", "streams", "s", "0"), 0, false},
+		{redis.NewStringCmd(ctx, "get", "k"), 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := blockTimeout(tt.cmd)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("blockTimeout(%v) = %s, %v, want %s, %v", tt.cmd.Args(), got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// 阻塞命令空闲超时返回时不应记为慢命令
+func TestHookBlockingCommandNotSlow(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.GetLogger()
+	out := logger.Out
+	logger.SetOutput(&buf)
+	defer logger.SetOutput(out)
+
+	c, _ := newTestClient(t, &Config{Stats: &StatsConfig{SlowThreshold: 50 * time.Millisecond}})
+	ctx := context.Background()
+
+	if err := c.BLPop(ctx, time.Second, "empty").Err(); err != redis.Nil {
+		t.Fatalf("BLPop error = %v, want redis.Nil", err)
+	}
+	if err := c.XGroupCreateMkStream(ctx, "jobs", "workers", "0").Err(); err != nil {
+		t.Fatal(err)
+	}
+	err := c.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group: "workers", Consumer: "c1", Streams: []string{"jobs", ">"}, Block: 200 * time.Millisecond,
+	}).Err()
+	if err != redis.Nil {
+		t.Fatalf("XReadGroup error = %v, want redis.Nil", err)
+	}
+
+	if strings.Contains(buf.String(), "redis slow command") {
+		t.Fatalf("blocking commands logged as slow: %s", buf.String())
+	}
+	stats := c.hook.Commands()
+	for _, name := range []string{"blpop", "xreadgroup"} {
+		s, ok := stats[name]
+		if !ok || s.Count != 1 {
+			t.Fatalf("%s stats = %+v, want one call", name, s)
+		}
+		if q := s.Quantile(1); q > 50*time.Millisecond {
+			t.Errorf("%s max latency bucket = %s, want <= 50ms", name, q)
+		}
+	}
+}
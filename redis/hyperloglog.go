@@ -0,0 +1,125 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 单次查询允许的最大时间桶数，避免误用导致超长命令
+const maxHyperLogLogBuckets = 10000
+
+// HyperLogLog 按时间分桶的基数计数器(如每小时 UV)，查询时合并区间内的桶
+// 所有桶共用 {name} hash tag，集群模式下位于同一 slot
+type HyperLogLog struct {
+	client    *RedisClient
+	name      string
+	bucket    time.Duration
+	retention time.Duration
+}
+
+// NewHyperLogLog 创建基数计数器，bucket 为分桶粒度(0 表示不分桶)，retention 为每个桶的保留时间(0 表示永久)
+func (r *RedisClient) NewHyperLogLog(name string, bucket, retention time.Duration) *HyperLogLog {
+	return &HyperLogLog{client: r, name: name, bucket: bucket, retention: retention}
+}
+
+// Add 将元素计入 t 所在的时间桶
+func (h *HyperLogLog) Add(ctx context.Context, t time.Time, elements ...string) error {
+	if len(elements) == 0 {
+		return nil
+	}
+	key := h.key(h.bucketStart(t))
+	args := make([]interface{}, len(elements))
+	for i, e := range elements {
+		args[i] = e
+	}
+	_, err := h.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.PFAdd(ctx, key, args...)
+		if h.retention > 0 && h.bucket > 0 {
+			pipe.Expire(ctx, key, h.retention)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("HyperLogLog.Add key=%s: %w", key, err)
+	}
+	return nil
+}
+
+// Count 返回 [from, to] 区间内去重后的元素数量(约0.81%标准误差)
+func (h *HyperLogLog) Count(ctx context.Context, from, to time.Time) (int64, error) {
+	keys, err := h.keys(from, to)
+	if err != nil {
+		return 0, err
+	}
+	n, err := h.client.PFCount(ctx, keys...).Result()
+	if err != nil {
+		return 0, fmt.Errorf("HyperLogLog.Count name=%s: %w", h.name, err)
+	}
+	return n, nil
+}
+
+// Merge 将 [from, to] 区间内的桶合并到 dest 桶(如按小时合并出天)，dest 与源桶同属 {name}
+func (h *HyperLogLog) Merge(ctx context.Context, dest string, from, to time.Time, ttl time.Duration) error {
+	keys, err := h.keys(from, to)
+	if err != nil {
+		return err
+	}
+	destKey := h.key(dest)
+	_, err = h.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.PFMerge(ctx, destKey, keys...)
+		if ttl > 0 {
+			pipe.Expire(ctx, destKey, ttl)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("HyperLogLog.Merge key=%s: %w", destKey, err)
+	}
+	return nil
+}
+
+// CountMerged 返回 Merge 生成的桶的计数
+func (h *HyperLogLog) CountMerged(ctx context.Context, dest string) (int64, error) {
+	n, err := h.client.PFCount(ctx, h.key(dest)).Result()
+	if err != nil {
+		return 0, fmt.Errorf("HyperLogLog.CountMerged key=%s: %w", h.key(dest), err)
+	}
+	return n, nil
+}
+
+func (h *HyperLogLog) keys(from, to time.Time) ([]string, error) {
+	if h.bucket <= 0 {
+		return []string{h.key("")}, nil
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("HyperLogLog name=%s: invalid range %s - %s", h.name, from, to)
+	}
+	start := from.Truncate(h.bucket)
+	n := int(to.Sub(start)/h.bucket) + 1
+	if n > maxHyperLogLogBuckets {
+		return nil, fmt.Errorf("HyperLogLog name=%s: range covers %d buckets, max %d", h.name, n, maxHyperLogLogBuckets)
+	}
+	keys := make([]string, 0, n)
+	for t := start; !t.After(to); t = t.Add(h.bucket) {
+		keys = append(keys, h.key(h.bucketStart(t)))
+	}
+	return keys, nil
+}
+
+func (h *HyperLogLog) bucketStart(t time.Time) string {
+	if h.bucket <= 0 {
+		return ""
+	}
+	return strconv.FormatInt(t.Truncate(h.bucket).Unix(), 10)
+}
+
+func (h *HyperLogLog) key(suffix string) string {
+	if suffix == "" {
+		return "hll:{" + h.name + "}"
+	}
+	return "hll:{" + h.name + "}:" + suffix
+}
@@ -0,0 +1,89 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestHyperLogLog(t *testing.T) {
+	c, s := newTestClient(t, nil)
+	ctx := context.Background()
+	h := c.NewHyperLogLog("uv", time.Hour, 2*time.Hour)
+	t0 := time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC)
+	t1 := t0.Add(time.Hour)
+
+	if err := h.Add(ctx, t0, "u1", "u2", "u3"); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	// 同一桶内重复元素只计一次
+	if err := h.Add(ctx, t0.Add(time.Minute), "u1"); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	// miniredis 的多 key PFCOUNT 为各 key 计数之和(Redis 为并集)，跨桶使用不重叠的元素
+	if err := h.Add(ctx, t1, "u4", "u5"); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := h.Add(ctx, t1); err != nil {
+		t.Fatalf("Add without elements: %v", err)
+	}
+
+	key := fmt.Sprintf("hll:{uv}:%d", t0.Truncate(time.Hour).Unix())
+	if ttl := s.TTL(key); ttl != 2*time.Hour {
+		t.Fatalf("TTL(%s) = %s, want 2h", key, ttl)
+	}
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     int64
+	}{
+		{"first bucket", t0.Truncate(time.Hour), t0, 3},
+		{"second bucket", t1, t1, 2},
+		{"both buckets", t0, t1, 5},
+		{"empty", t1.Add(time.Hour), t1.Add(2 * time.Hour), 0},
+	}
+	for _, tt := range tests {
+		if n, err := h.Count(ctx, tt.from, tt.to); err != nil || n != tt.want {
+			t.Errorf("%s: Count = %d, %v, want %d", tt.name, n, err, tt.want)
+		}
+	}
+
+	if err := h.Merge(ctx, "day", t0, t1, time.Minute); err != nil {
+		t.Fatalf("Merge: %v", err)
+	}
+	if n, err := h.CountMerged(ctx, "day"); err != nil || n != 5 {
+		t.Fatalf("CountMerged = %d, %v, want 5", n, err)
+	}
+	if ttl := s.TTL("hll:{uv}:day"); ttl != time.Minute {
+		t.Fatalf("merged TTL = %s, want 1m", ttl)
+	}
+
+	if _, err := h.Count(ctx, t1, t0); err == nil {
+		t.Error("Count with reversed range succeeded, want error")
+	}
+	if _, err := h.Count(ctx, t0, t0.Add(maxHyperLogLogBuckets*time.Hour)); err == nil {
+		t.Error("Count over too many buckets succeeded, want error")
+	}
+}
+
+// bucket 为0时不分桶，也不设置过期时间
+func TestHyperLogLogWithoutBuckets(t *testing.T) {
+	c, s := newTestClient(t, nil)
+	ctx := context.Background()
+	h := c.NewHyperLogLog("total", 0, time.Hour)
+	now := time.Now()
+	if err := h.Add(ctx, now, "a", "b"); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := h.Add(ctx, now.Add(-48*time.Hour), "b", "c"); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if n, err := h.Count(ctx, now, now.Add(-time.Hour)); err != nil || n != 3 {
+		t.Fatalf("Count = %d, %v, want 3", n, err)
+	}
+	if keys := s.Keys(); len(keys) != 1 || keys[0] != "hll:{total}" || s.TTL(keys[0]) != 0 {
+		t.Fatalf("keys = %v", keys)
+	}
+}
@@ -0,0 +1,90 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultNamespaceVersionTTL = time.Second
+
+// Key 以 ":" 拼接 key 片段，如 Key("user", 42, "profile") 返回 "user:42:profile"
+func Key(parts ...interface{}) string {
+	var b strings.Builder
+	for i, p := range parts {
+		if i > 0 {
+			b.WriteByte(':')
+		}
+		switch v := p.(type) {
+		case string:
+			b.WriteString(v)
+		default:
+			fmt.Fprint(&b, v)
+		}
+	}
+	return b.String()
+}
+
+// Namespace 带版本号的 key 命名空间，生成的 key 形如 name:v3:...
+// Bump 递增版本号后旧版本的 key 不再被访问，等待自身过期，从而一次性失效整个命名空间
+type Namespace struct {
+	client *RedisClient
+	name   string
+	ttl    time.Duration
+
+	mu        sync.Mutex
+	version   int64
+	expiresAt time.Time
+}
+
+// NewNamespace 创建命名空间，版本号在本地缓存 versionTTL(默认1s)，其他实例 Bump 后最长延迟该时间生效
+func (r *RedisClient) NewNamespace(name string, versionTTL time.Duration) *Namespace {
+	if versionTTL <= 0 {
+		versionTTL = defaultNamespaceVersionTTL
+	}
+	return &Namespace{client: r, name: name, ttl: versionTTL}
+}
+
+// Key 返回当前版本下的 key
+func (n *Namespace) Key(ctx context.Context, parts ...interface{}) (string, error) {
+	version, err := n.Version(ctx)
+	if err != nil {
+		return "", err
+	}
+	return n.name + ":v" + fmt.Sprint(version) + ":" + Key(parts...), nil
+}
+
+// Version 返回当前版本号，未 Bump 过时为0
+func (n *Namespace) Version(ctx context.Context) (int64, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if time.Now().Before(n.expiresAt) {
+		return n.version, nil
+	}
+	version, err := n.client.Get(ctx, n.versionKey()).Int64()
+	if err != nil && !IsRedisNil(err) {
+		return 0, fmt.Errorf("Namespace.Version name=%s: %w", n.name, err)
+	}
+	n.version = version
+	n.expiresAt = time.Now().Add(n.ttl)
+	return version, nil
+}
+
+// Bump 递增版本号，使命名空间下已有的 key 全部失效，返回新版本号
+func (n *Namespace) Bump(ctx context.Context) (int64, error) {
+	version, err := n.client.Incr(ctx, n.versionKey()).Result()
+	if err != nil {
+		return 0, fmt.Errorf("Namespace.Bump name=%s: %w", n.name, err)
+	}
+	n.mu.Lock()
+	n.version = version
+	n.expiresAt = time.Now().Add(n.ttl)
+	n.mu.Unlock()
+	return version, nil
+}
+
+func (n *Namespace) versionKey() string {
+	return "ns:" + n.name + ":version"
+}
@@ -0,0 +1,237 @@
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrLockNotObtained 锁已被其他持有者占用
+	ErrLockNotObtained = errors.New("redis: lock not obtained")
+	// ErrLockNotHeld 锁未持有或已过期
+	ErrLockNotHeld = errors.New("redis: lock not held")
+	// ErrLockAlreadyHeld 当前 Lock 已持有锁，需先 Unlock
+	ErrLockAlreadyHeld = errors.New("redis: lock already held")
+)
+
+var (
+	// 加锁成功时递增并返回 fencing token，失败返回0
+	lockAcquireScript = builtinScript("lock_acquire.lua")
+
+	// 仅当持有者匹配时删除
+	lockReleaseScript = builtinScript("lock_release.lua")
+
+	// 仅当持有者匹配时续期
+	lockRefreshScript = builtinScript("lock_refresh.lua")
+)
+
+// LockOption 锁配置项
+type LockOption func(*Lock)
+
+// WithLockBackoff 设置 LockCtx 重试的最小与最大退避时间，默认 50ms ~ 1s
+func WithLockBackoff(min, max time.Duration) LockOption {
+	return func(l *Lock) {
+		l.minBackoff, l.maxBackoff = min, max
+	}
+}
+
+// WithoutWatchdog 关闭自动续期，锁在 ttl 后自动过期
+func WithoutWatchdog() LockOption {
+	return func(l *Lock) {
+		l.watchdog = false
+	}
+}
+
+// Lock 基于 SET NX PX 的分布式锁
+// 加锁成功返回单调递增的 fencing token，下游存储可据此拒绝过期持有者的写入
+type Lock struct {
+	client     *RedisClient
+	key        string
+	fenceKey   string
+	ttl        time.Duration
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	watchdog   bool
+
+	mu    sync.Mutex
+	owner string
+	fence int64
+	stop  chan struct{}
+	lost  chan struct{}
+}
+
+// NewMutex 创建分布式锁，ttl 为锁的过期时间
+// 锁 key 与 fencing key 使用相同的 hash tag，保证在集群模式下位于同一 slot
+func (r *RedisClient) NewMutex(key string, ttl time.Duration, opts ...LockOption) *Lock {
+	tagged := key
+	if !hasHashTag(key) {
+		tagged = "{" + key + "}"
+	}
+	l := &Lock{
+		client:     r,
+		key:        "lock:" + tagged,
+		fenceKey:   "lock:" + tagged + ":fence",
+		ttl:        ttl,
+		minBackoff: 50 * time.Millisecond,
+		maxBackoff: time.Second,
+		watchdog:   true,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// TryLock 尝试加锁一次，锁被占用时返回 ErrLockNotObtained
+// 当前 Lock 已持有锁(包括锁已丢失但未 Unlock)时返回 ErrLockAlreadyHeld
+func (l *Lock) TryLock(ctx context.Context) (int64, error) {
+	if l.held() {
+		return 0, ErrLockAlreadyHeld
+	}
+	owner, err := randomToken()
+	if err != nil {
+		return 0, err
+	}
+	fence, err := lockAcquireScript.Run(WithNonIdempotent(ctx), l.client, []string{l.key, l.fenceKey},
+		owner, l.ttl.Milliseconds()).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("TryLock key=%s: %w", l.key, err)
+	}
+	if fence == 0 {
+		return 0, ErrLockNotObtained
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.owner, l.fence = owner, fence
+	l.lost = make(chan struct{})
+	if l.watchdog {
+		l.stop = make(chan struct{})
+		go l.renew(owner, l.stop, l.lost)
+	}
+	return fence, nil
+}
+
+// LockCtx 加锁，锁被占用时按指数退避重试直到成功或 ctx 结束
+func (l *Lock) LockCtx(ctx context.Context) (int64, error) {
+	backoff := l.minBackoff
+	for {
+		fence, err := l.TryLock(ctx)
+		if err == nil || !errors.Is(err, ErrLockNotObtained) {
+			return fence, err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return 0, ctx.Err()
+		case <-timer.C:
+		}
+		if backoff *= 2; backoff > l.maxBackoff {
+			backoff = l.maxBackoff
+		}
+	}
+}
+
+// Unlock 释放锁，锁已过期或被他人持有时返回 ErrLockNotHeld
+func (l *Lock) Unlock(ctx context.Context) error {
+	l.mu.Lock()
+	owner := l.owner
+	if l.stop != nil {
+		close(l.stop)
+		l.stop = nil
+	}
+	l.owner, l.fence = "", 0
+	l.mu.Unlock()
+
+	if owner == "" {
+		return ErrLockNotHeld
+	}
+	n, err := lockReleaseScript.Run(WithNonIdempotent(ctx), l.client, []string{l.key}, owner).Int64()
+	if err != nil {
+		return fmt.Errorf("Unlock key=%s: %w", l.key, err)
+	}
+	if n == 0 {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+func (l *Lock) held() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.owner != ""
+}
+
+// Token 返回当前持有的 fencing token，未持有时为0
+func (l *Lock) Token() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.fence
+}
+
+// Lost 锁在持有期间丢失(续期失败)时关闭
+func (l *Lock) Lost() <-chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.lost
+}
+
+// renew 看门狗，每 ttl/3 续期一次，超过 ttl 未续期成功则认为锁已丢失
+func (l *Lock) renew(owner string, stop, lost chan struct{}) {
+	interval := l.ttl / 3
+	if interval <= 0 {
+		interval = time.Millisecond
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	lastRenew := time.Now()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		n, err := lockRefreshScript.Run(ctx, l.client, []string{l.key}, owner, l.ttl.Milliseconds()).Int64()
+		cancel()
+		switch {
+		case err == nil && n == 1:
+			lastRenew = time.Now()
+			continue
+		case err == nil && n == 0:
+			// 锁已被他人持有或已过期
+		case time.Since(lastRenew) < l.ttl:
+			// 网络抖动，下个周期重试
+			continue
+		}
+		close(lost)
+		return
+	}
+}
+
+func hasHashTag(key string) bool {
+	start := strings.IndexByte(key, '{')
+	if start < 0 {
+		return false
+	}
+	end := strings.IndexByte(key[start+1:], '}')
+	return end > 0
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
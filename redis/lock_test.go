@@ -0,0 +1,43 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestLockTryLockWhileHeld(t *testing.T) {
+	c, _ := newTestClient(t, nil)
+	ctx := context.Background()
+	l := c.NewMutex("order:1", time.Second)
+
+	fence, err := l.TryLock(ctx)
+	if err != nil || fence != 1 {
+		t.Fatalf("TryLock = %d, %v, want 1, nil", fence, err)
+	}
+	goroutines := runtime.NumGoroutine()
+	if _, err := l.TryLock(ctx); !errors.Is(err, ErrLockAlreadyHeld) {
+		t.Fatalf("second TryLock error = %v, want ErrLockAlreadyHeld", err)
+	}
+	if _, err := l.LockCtx(ctx); !errors.Is(err, ErrLockAlreadyHeld) {
+		t.Fatalf("LockCtx error = %v, want ErrLockAlreadyHeld", err)
+	}
+	if got := runtime.NumGoroutine(); got > goroutines {
+		t.Fatalf("goroutines grew from %d to %d, watchdog leaked", goroutines, got)
+	}
+	if l.Token() != fence {
+		t.Fatalf("Token = %d, want %d", l.Token(), fence)
+	}
+
+	if err := l.Unlock(ctx); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if fence, err := l.TryLock(ctx); err != nil || fence != 2 {
+		t.Fatalf("TryLock after Unlock = %d, %v, want 2, nil", fence, err)
+	}
+	if err := l.Unlock(ctx); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+}
@@ -0,0 +1,13 @@
+local k = tonumber(ARGV[1])
+local result = {}
+local n = (#ARGV - 1) / k
+for i = 0, n - 1 do
+	local added = 0
+	for j = 1, k do
+		if redis.call("SETBIT", KEYS[1], ARGV[1 + i * k + j], 1) == 0 then
+			added = 1
+		end
+	end
+	result[i + 1] = added
+end
+return result
@@ -0,0 +1,14 @@
+local k = tonumber(ARGV[1])
+local result = {}
+local n = (#ARGV - 1) / k
+for i = 0, n - 1 do
+	local exists = 1
+	for j = 1, k do
+		if redis.call("GETBIT", KEYS[1], ARGV[1 + i * k + j]) == 0 then
+			exists = 0
+			break
+		end
+	end
+	result[i + 1] = exists
+end
+return result
@@ -0,0 +1,27 @@
+redis.replicate_commands()
+local burst = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local period = tonumber(ARGV[3])
+local emission_interval = period / rate
+local burst_offset = emission_interval * burst
+
+-- 减去固定偏移保证浮点精度
+local t = redis.call("TIME")
+local now = (tonumber(t[1]) - 1483228800) + tonumber(t[2]) / 1000000
+
+local tat = redis.call("GET", KEYS[1])
+if not tat then
+	tat = now
+else
+	tat = math.max(tonumber(tat), now)
+end
+
+local new_tat = tat + emission_interval
+local diff = now - (new_tat - burst_offset)
+if diff < 0 then
+	return {0, 0, tostring(-diff), tostring(tat - now)}
+end
+
+local reset_after = new_tat - now
+redis.call("SET", KEYS[1], tostring(new_tat), "EX", math.ceil(reset_after))
+return {1, math.floor(diff / emission_interval + 1e-6), "0", tostring(reset_after)}
@@ -0,0 +1,14 @@
+local factor = tonumber(ARGV[1])
+local min = tonumber(ARGV[2])
+local members = redis.call("ZRANGE", KEYS[1], 0, -1, "WITHSCORES")
+local removed = 0
+for i = 1, #members, 2 do
+	local score = tonumber(members[i + 1]) * factor
+	if score < min then
+		redis.call("ZREM", KEYS[1], members[i])
+		removed = removed + 1
+	else
+		redis.call("ZADD", KEYS[1], score, members[i])
+	end
+end
+return removed
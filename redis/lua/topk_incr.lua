@@ -0,0 +1,7 @@
+local score = redis.call("ZINCRBY", KEYS[1], ARGV[2], ARGV[1])
+local size = redis.call("ZCARD", KEYS[1])
+local capacity = tonumber(ARGV[3])
+if size > capacity then
+	redis.call("ZREMRANGEBYRANK", KEYS[1], 0, size - capacity - 1)
+end
+return score
@@ -0,0 +1,296 @@
+package redis
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// keySpec 描述命令参数中 key 的位置
+type keySpec struct {
+	// first 第一个 key 的参数下标，0 表示需特殊处理
+	first int
+	// last 最后一个 key 的下标，负数表示倒数(-1 为最后一个参数)，0 表示与 first 相同
+	last int
+	// step key 之间的间隔(MSET 为2)
+	step int
+	// numkeys 参数下标，key 紧随其后，0 表示无 numkeys 参数
+	numkeys int
+}
+
+var (
+	specFirst     = keySpec{first: 1}
+	specAll       = keySpec{first: 1, last: -1, step: 1}
+	specPairs     = keySpec{first: 1, last: -1, step: 2}
+	specFirstTwo  = keySpec{first: 1, last: 2, step: 1}
+	specSecond    = keySpec{first: 2}
+	specAllButEnd = keySpec{first: 1, last: -2, step: 1}
+)
+
+// keySpecs 需要加前缀的命令，未列出的命令(如 PING、INFO)原样发送
+// Pub/Sub 频道名不属于 key，不加前缀，需按环境隔离时用 KeyPrefix() 拼接频道名
+var keySpecs = map[string]keySpec{
+	// string
+	"get": specFirst, "set": specFirst, "setnx": specFirst, "setex": specFirst, "psetex": specFirst,
+	"getset": specFirst, "getdel": specFirst, "getex": specFirst, "append": specFirst, "strlen": specFirst,
+	"incr": specFirst, "incrby": specFirst, "incrbyfloat": specFirst, "decr": specFirst, "decrby": specFirst,
+	"getrange": specFirst, "setrange": specFirst, "getbit": specFirst, "setbit": specFirst,
+	"bitcount": specFirst, "bitpos": specFirst, "bitfield": specFirst, "bitfield_ro": specFirst,
+	"mget": specAll, "mset": specPairs, "msetnx": specPairs,
+	// generic
+	"del": specAll, "unlink": specAll, "exists": specAll, "touch": specAll, "watch": specAll,
+	"expire": specFirst, "pexpire": specFirst, "expireat": specFirst, "pexpireat": specFirst,
+	"expiretime": specFirst, "pexpiretime": specFirst, "ttl": specFirst, "pttl": specFirst,
+	"persist": specFirst, "type": specFirst, "dump": specFirst, "restore": specFirst, "sort": specFirst,
+	"sort_ro": specFirst, "rename": specFirstTwo, "renamenx": specFirstTwo, "copy": specFirstTwo,
+	"object": specSecond, "memory": specSecond,
+	// hash
+	"hget": specFirst, "hset": specFirst, "hsetnx": specFirst, "hmset": specFirst, "hmget": specFirst,
+	"hdel": specFirst, "hexists": specFirst, "hgetall": specFirst, "hkeys": specFirst, "hvals": specFirst,
+	"hlen": specFirst, "hincrby": specFirst, "hincrbyfloat": specFirst, "hscan": specFirst,
+	"hstrlen": specFirst, "hrandfield": specFirst, "hexpire": specFirst, "hpexpire": specFirst,
+	"httl": specFirst, "hpttl": specFirst, "hpersist": specFirst,
+	// list
+	"lpush": specFirst, "rpush": specFirst, "lpushx": specFirst, "rpushx": specFirst, "lpop": specFirst,
+	"rpop": specFirst, "llen": specFirst, "lrange": specFirst, "lindex": specFirst, "lset": specFirst,
+	"lrem": specFirst, "ltrim": specFirst, "linsert": specFirst, "lpos": specFirst,
+	"rpoplpush": specFirstTwo, "lmove": specFirstTwo, "brpoplpush": specFirstTwo, "blmove": specFirstTwo,
+	"blpop": specAllButEnd, "brpop": specAllButEnd,
+	"lmpop": {numkeys: 1}, "blmpop": {numkeys: 2},
+	// set
+	"sadd": specFirst, "srem": specFirst, "smembers": specFirst, "sismember": specFirst,
+	"smismember": specFirst, "scard": specFirst, "spop": specFirst, "srandmember": specFirst,
+	"sscan": specFirst, "sinter": specAll, "sunion": specAll, "sdiff": specAll, "sinterstore": specAll,
+	"sunionstore": specAll, "sdiffstore": specAll, "smove": specFirstTwo, "sintercard": {numkeys: 1},
+	// sorted set
+	"zadd": specFirst, "zincrby": specFirst, "zrem": specFirst, "zscore": specFirst, "zmscore": specFirst,
+	"zrank": specFirst, "zrevrank": specFirst, "zrange": specFirst, "zrevrange": specFirst,
+	"zrangebyscore": specFirst, "zrevrangebyscore": specFirst, "zrangebylex": specFirst,
+	"zrevrangebylex": specFirst, "zcard": specFirst, "zcount": specFirst, "zlexcount": specFirst,
+	"zremrangebyrank": specFirst, "zremrangebyscore": specFirst, "zremrangebylex": specFirst,
+	"zscan": specFirst, "zpopmin": specFirst, "zpopmax": specFirst, "zrandmember": specFirst,
+	"zrangestore": specFirstTwo, "bzpopmin": specAllButEnd, "bzpopmax": specAllButEnd,
+	"zunion": {numkeys: 1}, "zinter": {numkeys: 1}, "zdiff": {numkeys: 1}, "zintercard": {numkeys: 1},
+	"zmpop": {numkeys: 1}, "bzmpop": {numkeys: 2},
+	"zunionstore": {first: 1, numkeys: 2}, "zinterstore": {first: 1, numkeys: 2}, "zdiffstore": {first: 1, numkeys: 2},
+	// hyperloglog
+	"pfadd": specFirst, "pfcount": specAll, "pfmerge": specAll,
+	// geo
+	"geoadd": specFirst, "geopos": specFirst, "geodist": specFirst, "geohash": specFirst,
+	"georadius": specFirst, "georadius_ro": specFirst, "georadiusbymember": specFirst,
+	"georadiusbymember_ro": specFirst, "geosearch": specFirst, "geosearchstore": specFirstTwo,
+	// stream
+	"xadd": specFirst, "xlen": specFirst, "xrange": specFirst, "xrevrange": specFirst, "xdel": specFirst,
+	"xtrim": specFirst, "xack": specFirst, "xpending": specFirst, "xclaim": specFirst,
+	"xautoclaim": specFirst, "xgroup": specSecond, "xinfo": specSecond,
+	// script
+	"eval": {numkeys: 2}, "evalsha": {numkeys: 2}, "eval_ro": {numkeys: 2}, "evalsha_ro": {numkeys: 2},
+	"fcall": {numkeys: 2}, "fcall_ro": {numkeys: 2},
+}
+
+// prefixHook 为命令中的 key 透明地加上前缀，并去掉 SCAN/KEYS 等返回结果中的前缀
+type prefixHook struct {
+	prefix string
+}
+
+func (h prefixHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (h prefixHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		orig := h.rewrite(cmd)
+		err := next(ctx, cmd)
+		restoreArgs(cmd, orig)
+		h.strip(cmd)
+		return err
+	}
+}
+
+func (h prefixHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		origs := make([][]interface{}, len(cmds))
+		for i, cmd := range cmds {
+			origs[i] = h.rewrite(cmd)
+		}
+		err := next(ctx, cmds)
+		for i, cmd := range cmds {
+			restoreArgs(cmd, origs[i])
+			h.strip(cmd)
+		}
+		return err
+	}
+}
+
+// rewrite 原地修改命令参数，返回修改前的参数副本
+// 执行后需恢复原参数：SCAN 迭代器等会复用同一个命令再次执行
+func (h prefixHook) rewrite(cmd redis.Cmder) []interface{} {
+	args := cmd.Args()
+	name := cmd.Name()
+	_, ok := keySpecs[name]
+	switch name {
+	case "scan", "keys", "xread", "xreadgroup":
+		ok = true
+	}
+	if !ok {
+		return nil
+	}
+	orig := make([]interface{}, len(args))
+	copy(orig, args)
+
+	switch name {
+	case "scan":
+		// 仅处理 MATCH 模式，未指定时在结果中过滤
+		for i := 2; i < len(args)-1; i++ {
+			if strings.EqualFold(argString(args[i]), "match") {
+				args[i+1] = h.prefix + argString(args[i+1])
+				break
+			}
+		}
+		return orig
+	case "keys":
+		if len(args) > 1 {
+			args[1] = h.prefix + argString(args[1])
+		}
+		return orig
+	case "xread", "xreadgroup":
+		// STREAMS key1 key2 ... id1 id2 ...
+		for i := 1; i < len(args); i++ {
+			if strings.EqualFold(argString(args[i]), "streams") {
+				n := (len(args) - i - 1) / 2
+				h.prefixRange(args, i+1, i+n, 1)
+				break
+			}
+		}
+		return orig
+	}
+
+	spec := keySpecs[name]
+	if spec.first > 0 {
+		last := spec.last
+		switch {
+		case last == 0:
+			last = spec.first
+		case last < 0:
+			last = len(args) + last
+		}
+		step := spec.step
+		if step == 0 {
+			step = 1
+		}
+		h.prefixRange(args, spec.first, last, step)
+	}
+	if spec.numkeys > 0 && spec.numkeys < len(args) {
+		n, err := strconv.Atoi(argString(args[spec.numkeys]))
+		if err == nil {
+			h.prefixRange(args, spec.numkeys+1, spec.numkeys+n, 1)
+		}
+	}
+	return orig
+}
+
+func restoreArgs(cmd redis.Cmder, orig []interface{}) {
+	if orig != nil {
+		copy(cmd.Args(), orig)
+	}
+}
+
+func (h prefixHook) prefixRange(args []interface{}, first, last, step int) {
+	if last >= len(args) {
+		last = len(args) - 1
+	}
+	for i := first; i <= last; i += step {
+		switch v := args[i].(type) {
+		case string:
+			args[i] = h.prefix + v
+		case []byte:
+			args[i] = h.prefix + string(v)
+		}
+	}
+}
+
+// strip 去掉返回结果中的前缀，使调用方看到的 key 与传入时一致
+func (h prefixHook) strip(cmd redis.Cmder) {
+	if cmd.Err() != nil {
+		return
+	}
+	switch c := cmd.(type) {
+	case *redis.ScanCmd:
+		if c.Name() != "scan" {
+			return
+		}
+		keys, cursor := c.Val()
+		c.SetVal(h.filter(keys), cursor)
+	case *redis.StringSliceCmd:
+		switch c.Name() {
+		case "keys":
+			c.SetVal(h.filter(c.Val()))
+		case "blpop", "brpop":
+			// 返回 [key, value]
+			if val := c.Val(); len(val) == 2 {
+				val[0] = strings.TrimPrefix(val[0], h.prefix)
+			}
+		}
+	case *redis.XStreamSliceCmd:
+		for i := range c.Val() {
+			c.Val()[i].Stream = strings.TrimPrefix(c.Val()[i].Stream, h.prefix)
+		}
+	case *redis.ZWithKeyCmd:
+		if z := c.Val(); z != nil {
+			z.Key = strings.TrimPrefix(z.Key, h.prefix)
+		}
+	}
+}
+
+// filter 仅保留带前缀的 key 并去掉前缀
+func (h prefixHook) filter(keys []string) []string {
+	out := keys[:0]
+	for _, k := range keys {
+		if strings.HasPrefix(k, h.prefix) {
+			out = append(out, k[len(h.prefix):])
+		}
+	}
+	return out
+}
+
+func argString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	}
+	return ""
+}
+
+// KeyPrefix 返回配置的 key 前缀
+func (r *RedisClient) KeyPrefix() string {
+	return r.config.KeyPrefix
+}
+
+// Watch 乐观锁事务，key 与事务中的命令同样加前缀
+// 集群模式下 WATCH 与事务在节点客户端上执行，节点客户端没有前缀钩子，
+// 因此先为 key 加前缀(同时按加前缀后的 key 选择节点)，再为事务添加前缀钩子
+func (r *RedisClient) Watch(ctx context.Context, fn func(*redis.Tx) error, keys ...string) error {
+	if _, ok := r.UniversalClient.(*redis.ClusterClient); !ok || r.config.KeyPrefix == "" {
+		return r.UniversalClient.Watch(ctx, fn, keys...)
+	}
+	h := prefixHook{prefix: r.config.KeyPrefix}
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = h.prefix + key
+	}
+	return r.UniversalClient.Watch(ctx, func(tx *redis.Tx) error {
+		tx.AddHook(h)
+		return fn(tx)
+	}, prefixed...)
+}
@@ -0,0 +1,204 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestPrefixWatch(t *testing.T) {
+	s := miniredis.RunT(t)
+	for _, cfg := range []*Config{
+		{Address: s.Addr(), KeyPrefix: "single:"},
+		{Mode: ModeCluster, ClusterAddrs: []string{s.Addr()}, KeyPrefix: "cluster:"},
+	} {
+		c, err := NewClient(cfg)
+		if err != nil {
+			t.Fatalf("NewClient(%s): %v", cfg.Mode, err)
+		}
+		ctx := context.Background()
+		if err := c.Set(ctx, "counter", 1, 0).Err(); err != nil {
+			t.Fatal(err)
+		}
+		err = c.Watch(ctx, func(tx *redis.Tx) error {
+			n, err := tx.Get(ctx, "counter").Int()
+			if err != nil {
+				return err
+			}
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.Set(ctx, "counter", n+1, 0)
+				return nil
+			})
+			return err
+		}, "counter")
+		if err != nil {
+			t.Fatalf("Watch(%s): %v", cfg.KeyPrefix, err)
+		}
+		if got, _ := s.Get(cfg.KeyPrefix + "counter"); got != "2" {
+			t.Errorf("%scounter = %q, want 2", cfg.KeyPrefix, got)
+		}
+		if s.Exists("counter") {
+			t.Errorf("%s: unprefixed key written", cfg.KeyPrefix)
+		}
+		_ = c.Close()
+	}
+}
+
+func TestPrefixRewrite(t *testing.T) {
+	h := prefixHook{prefix: "p:"}
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		cmd  redis.Cmder
+		want []interface{}
+	}{
+		{"get", redis.NewStringCmd(ctx, "get", "k"), []interface{}{"get", "p:k"}},
+		{"mset", redis.NewStatusCmd(ctx, "mset", "a", "1", "b", "2"), []interface{}{"mset", "p:a", "1", "p:b", "2"}},
+		{"rename", redis.NewStatusCmd(ctx, "rename", "a", "b"), []interface{}{"rename", "p:a", "p:b"}},
+		{"blpop", redis.NewStringSliceCmd(ctx, "blpop", "a", "b", 0), []interface{}{"blpop", "p:a", "p:b", 0}},
+		{"lmpop", redis.NewKeyValuesCmd(ctx, "lmpop", 2, "a", "b", "left"),
+			[]interface{}{"lmpop", 2, "p:a", "p:b", "left"}},
+		{"zunionstore", redis.NewIntCmd(ctx, "zunionstore", "dst", 2, "a", "b"),
+			[]interface{}{"zunionstore", "p:dst", 2, "p:a", "p:b"}},
+		{"eval", redis.NewCmd(ctx, "eval", "return 1", 1, "a", "argv"),
+			[]interface{}{"eval", "return 1", 1, "p:a", "argv"}},
+		{"xreadgroup", redis.NewXStreamSliceCmd(ctx, "xreadgroup", "group", "g", "c", "streams", "a", "b", ">", ">"),
+			[]interface{}{"xreadgroup", "group", "g", "c", "streams", "p:a", "p:b", ">", ">"}},
+		{"scan match", redis.NewScanCmd(ctx, nil, "scan", 0, "match", "user:*"),
+			[]interface{}{"scan", 0, "match", "p:user:*"}},
+		{"keys", redis.NewStringSliceCmd(ctx, "keys", "*"), []interface{}{"keys", "p:*"}},
+		{"bytes", redis.NewStringCmd(ctx, "get", []byte("k")), []interface{}{"get", "p:k"}},
+		{"ping", redis.NewStatusCmd(ctx, "ping"), []interface{}{"ping"}},
+		{"publish", redis.NewIntCmd(ctx, "publish", "channel", "msg"), []interface{}{"publish", "channel", "msg"}},
+	}
+	for _, tt := range tests {
+		orig := append([]interface{}(nil), tt.cmd.Args()...)
+		saved := h.rewrite(tt.cmd)
+		if got := fmt.Sprint(tt.cmd.Args()); got != fmt.Sprint(tt.want) {
+			t.Errorf("%s: rewritten args = %s, want %v", tt.name, got, tt.want)
+		}
+		restoreArgs(tt.cmd, saved)
+		if got := fmt.Sprint(tt.cmd.Args()); got != fmt.Sprint(orig) {
+			t.Errorf("%s: restored args = %s, want %v", tt.name, got, orig)
+		}
+	}
+}
+
+func TestPrefixPipeline(t *testing.T) {
+	s := miniredis.RunT(t)
+	for _, cfg := range []*Config{
+		{Address: s.Addr(), KeyPrefix: "single:"},
+		{Mode: ModeCluster, ClusterAddrs: []string{s.Addr()}, KeyPrefix: "cluster:"},
+	} {
+		c, err := NewClient(cfg)
+		if err != nil {
+			t.Fatalf("NewClient(%s): %v", cfg.Mode, err)
+		}
+		ctx := context.Background()
+		p := cfg.KeyPrefix
+
+		var mget *redis.SliceCmd
+		var incr *redis.IntCmd
+		cmds, err := c.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.MSet(ctx, "{p}a", "1", "{p}b", "2")
+			incr = pipe.Incr(ctx, "{p}a")
+			mget = pipe.MGet(ctx, "{p}a", "{p}b", "{p}none")
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Pipelined(%s): %v", p, err)
+		}
+		if incr.Val() != 2 || fmt.Sprint(mget.Val()) != "[2 2 <nil>]" {
+			t.Errorf("%s: INCR = %d, MGET = %v", p, incr.Val(), mget.Val())
+		}
+		// 执行后恢复调用方传入的 key
+		if got := fmt.Sprint(cmds[0].Args()); got != "[mset {p}a 1 {p}b 2]" {
+			t.Errorf("%s: pipeline args after exec = %s", p, got)
+		}
+
+		_, err = c.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.ZAdd(ctx, "{p}z1", redis.Z{Score: 1, Member: "m"})
+			pipe.ZAdd(ctx, "{p}z2", redis.Z{Score: 2, Member: "m"})
+			pipe.ZUnionStore(ctx, "{p}zu", &redis.ZStore{Keys: []string{"{p}z1", "{p}z2"}})
+			pipe.Expire(ctx, "{p}zu", time.Minute)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("TxPipelined(%s): %v", p, err)
+		}
+
+		for _, key := range []string{"{p}a", "{p}b", "{p}z1", "{p}z2", "{p}zu"} {
+			if !s.Exists(p + key) {
+				t.Errorf("%s: key %s not written with prefix", p, key)
+			}
+			if s.Exists(key) {
+				t.Errorf("%s: unprefixed key %s written", p, key)
+			}
+		}
+		if score, _ := s.ZScore(p+"{p}zu", "m"); score != 3 {
+			t.Errorf("%s: ZUNIONSTORE score = %g, want 3", p, score)
+		}
+		if ttl := s.TTL(p + "{p}zu"); ttl != time.Minute {
+			t.Errorf("%s: TTL = %s, want 1m", p, ttl)
+		}
+		_ = c.Close()
+	}
+}
+
+func TestPrefixScript(t *testing.T) {
+	s := miniredis.RunT(t)
+	for _, cfg := range []*Config{
+		{Address: s.Addr(), KeyPrefix: "single:"},
+		{Mode: ModeCluster, ClusterAddrs: []string{s.Addr()}, KeyPrefix: "cluster:"},
+	} {
+		c, err := NewClient(cfg)
+		if err != nil {
+			t.Fatalf("NewClient(%s): %v", cfg.Mode, err)
+		}
+		ctx := context.Background()
+		p := cfg.KeyPrefix
+		s.FlushAll()
+
+		// KEYS 加前缀，ARGV 原样传递
+		script := redis.NewScript(`redis.call("SET", KEYS[1], ARGV[1]); return {KEYS[1], ARGV[1]}`)
+		for _, run := range []struct {
+			name string
+			fn   func() *redis.Cmd
+		}{
+			{"eval", func() *redis.Cmd { return script.Eval(ctx, c, []string{"{s}k"}, "{s}v") }},
+			{"evalsha", func() *redis.Cmd { return script.EvalSha(ctx, c, []string{"{s}k"}, "{s}v") }},
+			{"run", func() *redis.Cmd { return script.Run(ctx, c, []string{"{s}k"}, "{s}v") }},
+		} {
+			got, err := run.fn().StringSlice()
+			if err != nil {
+				t.Fatalf("%s %s: %v", p, run.name, err)
+			}
+			if got[0] != p+"{s}k" || got[1] != "{s}v" {
+				t.Errorf("%s %s: script saw %v", p, run.name, got)
+			}
+			if v, _ := s.Get(p + "{s}k"); v != "{s}v" {
+				t.Errorf("%s %s: %s{s}k = %q", p, run.name, p, v)
+			}
+		}
+
+		// 在 pipeline 中执行脚本
+		var cmd *redis.Cmd
+		if _, err := c.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			cmd = script.Eval(ctx, pipe, []string{"{s}pipe"}, "x")
+			return nil
+		}); err != nil {
+			t.Fatalf("%s pipelined eval: %v", p, err)
+		}
+		if got, _ := cmd.StringSlice(); len(got) != 2 || got[0] != p+"{s}pipe" || !s.Exists(p+"{s}pipe") {
+			t.Errorf("%s pipelined eval = %v", p, got)
+		}
+		if s.Exists("{s}k") || s.Exists("{s}pipe") {
+			t.Errorf("%s: unprefixed key written by script", p)
+		}
+		_ = c.Close()
+	}
+}
@@ -0,0 +1,212 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/tiamxu/kit/log"
+)
+
+const (
+	subscribeHealthCheck = 30 * time.Second
+	subscribeMinBackoff  = 100 * time.Millisecond
+	subscribeMaxBackoff  = 10 * time.Second
+)
+
+// Message 订阅收到的消息
+type Message struct {
+	Channel string
+	// Pattern 模式订阅时匹配的模式
+	Pattern string
+	Payload []byte
+
+	client *RedisClient
+}
+
+// Decode 使用与 PublishModel 相同的编码方式解码消息
+func (m *Message) Decode(v interface{}) error {
+	return m.client.DecodeModel(m.Payload, v)
+}
+
+// MessageHandler 消息处理函数
+type MessageHandler func(ctx context.Context, msg *Message) error
+
+type subscribeOptions struct {
+	pattern   bool
+	workers   int
+	queueSize int
+}
+
+// SubscribeOption 订阅配置项
+type SubscribeOption func(*subscribeOptions)
+
+// WithPattern 按模式订阅(PSUBSCRIBE)，channels 视为 glob 模式
+func WithPattern() SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.pattern = true
+	}
+}
+
+// WithWorkers 设置处理消息的 worker 数，默认1(保证顺序)
+func WithWorkers(n int) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.workers = n
+	}
+}
+
+// WithQueueSize 设置待处理消息的缓冲长度，缓冲满时暂停接收，默认100
+func WithQueueSize(n int) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.queueSize = n
+	}
+}
+
+// PublishModel 编码并发布消息，编码方式与 SetModelToCache 一致
+func (r *RedisClient) PublishModel(ctx context.Context, channel string, v interface{}) error {
+	data, err := r.EncodeModel(v)
+	if err != nil {
+		return fmt.Errorf("PublishModel[encode] channel=%s: %w", channel, err)
+	}
+	if err := r.UniversalClient.Publish(ctx, channel, data).Err(); err != nil {
+		return fmt.Errorf("PublishModel channel=%s: %w", channel, err)
+	}
+	return nil
+}
+
+// SubscribeHandler 订阅频道并由 worker 池处理消息，阻塞直到 ctx 结束
+// 网络错误时自动重连并重新订阅，处理函数返回的错误仅记录日志
+func (r *RedisClient) SubscribeHandler(ctx context.Context, channels []string, handler MessageHandler, opts ...SubscribeOption) error {
+	if len(channels) == 0 {
+		return fmt.Errorf("channels cannot be empty")
+	}
+	o := &subscribeOptions{workers: 1, queueSize: 100}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.workers <= 0 {
+		o.workers = 1
+	}
+	if o.queueSize < 0 {
+		o.queueSize = 0
+	}
+
+	msgs := make(chan *Message, o.queueSize)
+	var wg sync.WaitGroup
+	for i := 0; i < o.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for msg := range msgs {
+				if err := safeHandleMessage(ctx, handler, msg); err != nil {
+					log.GetLogger().Warnf("redis subscribe handle message from %s failed: %v", msg.Channel, err)
+				}
+			}
+		}()
+	}
+	defer func() {
+		close(msgs)
+		wg.Wait()
+	}()
+
+	backoff := subscribeMinBackoff
+	for {
+		err := r.receive(ctx, channels, o.pattern, msgs, func() { backoff = subscribeMinBackoff })
+		if ctx.Err() != nil {
+			return nil
+		}
+		log.GetLogger().Warnf("redis subscribe %v interrupted, resubscribe in %s: %v", channels, backoff, err)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+		if backoff *= 2; backoff > subscribeMaxBackoff {
+			backoff = subscribeMaxBackoff
+		}
+	}
+}
+
+// SubscribeTyped 订阅并将消息解码为 T 后交给 handler
+func SubscribeTyped[T any](ctx context.Context, r *RedisClient, channels []string,
+	handler func(ctx context.Context, channel string, v T) error, opts ...SubscribeOption) error {
+	return r.SubscribeHandler(ctx, channels, func(ctx context.Context, msg *Message) error {
+		var v T
+		if err := msg.Decode(&v); err != nil {
+			return fmt.Errorf("decode message: %w", err)
+		}
+		return handler(ctx, msg.Channel, v)
+	}, opts...)
+}
+
+// receive 建立一次订阅并持续接收，连接异常时返回错误
+func (r *RedisClient) receive(ctx context.Context, channels []string, pattern bool, msgs chan<- *Message, onSubscribed func()) error {
+	var ps *redis.PubSub
+	if pattern {
+		ps = r.UniversalClient.PSubscribe(ctx, channels...)
+	} else {
+		ps = r.UniversalClient.Subscribe(ctx, channels...)
+	}
+	defer ps.Close()
+
+	// 阻塞读取不响应 ctx 取消，需关闭连接使其返回
+	stop := context.AfterFunc(ctx, func() { _ = ps.Close() })
+	defer stop()
+
+	// 等待订阅确认
+	if _, err := ps.Receive(ctx); err != nil {
+		return err
+	}
+	onSubscribed()
+
+	pinged := false
+	for {
+		m, err := ps.ReceiveTimeout(ctx, subscribeHealthCheck)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() && !pinged {
+				// 长时间无消息，发送 ping 检测连接是否存活
+				if err := ps.Ping(ctx); err != nil {
+					return err
+				}
+				pinged = true
+				continue
+			}
+			return err
+		}
+		pinged = false
+
+		switch m := m.(type) {
+		case *redis.Message:
+			msg := &Message{
+				Channel: m.Channel,
+				Pattern: m.Pattern,
+				Payload: []byte(m.Payload),
+				client:  r,
+			}
+			select {
+			case msgs <- msg:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		default:
+			// 订阅确认与 pong 无需处理
+		}
+	}
+}
+
+func safeHandleMessage(ctx context.Context, handler MessageHandler, msg *Message) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panic: %v", r)
+		}
+	}()
+	return handler(ctx, msg)
+}
@@ -0,0 +1,84 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type pubsubModel struct {
+	ID   int64  `json:"id" msgpack:"id"`
+	Name string `json:"name" msgpack:"name"`
+}
+
+// waitSubscribed 等待订阅建立，避免消息在订阅前发布而丢失
+func waitSubscribed(t *testing.T, c *RedisClient, channel string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		n, err := c.PubSubNumSub(context.Background(), channel).Result()
+		if err == nil && n[channel] > 0 {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("subscription on %s not established", channel)
+}
+
+func TestPublishModelSubscribeTyped(t *testing.T) {
+	c, _ := newTestClient(t, &Config{Codec: "msgpack"})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	got := make(chan pubsubModel, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- SubscribeTyped(ctx, c, []string{"events"}, func(ctx context.Context, channel string, v pubsubModel) error {
+			got <- v
+			return nil
+		})
+	}()
+	waitSubscribed(t, c, "events")
+
+	want := pubsubModel{ID: 7, Name: "seven"}
+	if err := c.PublishModel(ctx, "events", want); err != nil {
+		t.Fatalf("PublishModel: %v", err)
+	}
+	select {
+	case v := <-got:
+		if v != want {
+			t.Fatalf("received %+v, want %+v", v, want)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("message not received")
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("SubscribeTyped returned %v after cancel", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("SubscribeTyped did not return after cancel")
+	}
+}
+
+// 嵌入的 go-redis Publish/Subscribe 未被扩展方法遮蔽
+func TestEmbeddedPubSub(t *testing.T) {
+	c, _ := newTestClient(t, nil)
+	ctx := context.Background()
+
+	ps := c.Subscribe(ctx, "raw")
+	defer ps.Close()
+	if _, err := ps.Receive(ctx); err != nil {
+		t.Fatalf("Receive subscription: %v", err)
+	}
+	if err := c.Publish(ctx, "raw", "hello").Err(); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	msg, err := ps.ReceiveMessage(ctx)
+	if err != nil || msg.Payload != "hello" {
+		t.Fatalf("ReceiveMessage = %v, %v, want hello", msg, err)
+	}
+}
@@ -0,0 +1,313 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/tiamxu/kit/log"
+	kitredis "github.com/tiamxu/kit/redis"
+)
+
+const (
+	fieldPayload = "payload"
+	fieldRetry   = "retry"
+	fieldError   = "error"
+	fieldSource  = "source_id"
+)
+
+// Config 队列配置
+type Config struct {
+	// Stream 队列对应的 stream key
+	Stream string `yaml:"stream"`
+	// Group 消费组名称
+	Group string `yaml:"group"`
+	// Consumer 消费者名称，默认 hostname-pid
+	Consumer string `yaml:"consumer"`
+	// Concurrency 并发处理的 worker 数，默认1
+	Concurrency int `yaml:"concurrency"`
+	// MaxRetries 处理失败后的最大重试次数，超过后转入死信队列，默认3
+	MaxRetries int `yaml:"max_retries"`
+	// DeadLetterStream 死信队列 stream key，默认 Stream + ":dead"
+	DeadLetterStream string `yaml:"dead_letter_stream"`
+	// MaxLen stream 近似最大长度，0 表示不裁剪
+	MaxLen int64 `yaml:"max_len"`
+	// BlockTimeout 无消息时 XREADGROUP 阻塞时长，默认5s
+	BlockTimeout time.Duration `yaml:"block_timeout"`
+	// ClaimInterval 检查其他消费者超时未确认消息的间隔，默认30s
+	ClaimInterval time.Duration `yaml:"claim_interval"`
+	// ClaimMinIdle 消息未确认超过该时长后被重新认领，默认1m
+	ClaimMinIdle time.Duration `yaml:"claim_min_idle"`
+}
+
+// Message 队列消息
+type Message[T any] struct {
+	ID      string
+	Payload T
+	// Retry 已重试次数
+	Retry int
+}
+
+// Handler 消息处理函数，返回错误时消息会被重试
+type Handler[T any] func(ctx context.Context, msg *Message[T]) error
+
+// Queue 基于 Redis Streams 与消费组的可靠任务队列
+// 消息负载使用与 SetModelToCache 相同的编码方式
+type Queue[T any] struct {
+	client *kitredis.RedisClient
+	cfg    Config
+}
+
+// New 创建队列，消费组不存在时自动创建
+func New[T any](client *kitredis.RedisClient, cfg Config) (*Queue[T], error) {
+	if cfg.Stream == "" || cfg.Group == "" {
+		return nil, fmt.Errorf("stream and group cannot be empty")
+	}
+	if cfg.Consumer == "" {
+		host, _ := os.Hostname()
+		cfg.Consumer = fmt.Sprintf("%s-%d", host, os.Getpid())
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = 3
+	}
+	if cfg.DeadLetterStream == "" {
+		cfg.DeadLetterStream = cfg.Stream + ":dead"
+	}
+	if cfg.BlockTimeout <= 0 {
+		cfg.BlockTimeout = 5 * time.Second
+	}
+	if cfg.ClaimInterval <= 0 {
+		cfg.ClaimInterval = 30 * time.Second
+	}
+	if cfg.ClaimMinIdle <= 0 {
+		cfg.ClaimMinIdle = time.Minute
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err := client.XGroupCreateMkStream(ctx, cfg.Stream, cfg.Group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return nil, fmt.Errorf("create consumer group %s: %w", cfg.Group, err)
+	}
+	return &Queue[T]{client: client, cfg: cfg}, nil
+}
+
+// Enqueue 投递消息，返回消息ID
+func (q *Queue[T]) Enqueue(ctx context.Context, payload T) (string, error) {
+	data, err := q.client.EncodeModel(payload)
+	if err != nil {
+		return "", fmt.Errorf("Enqueue[encode] stream=%s: %w", q.cfg.Stream, err)
+	}
+	return q.add(ctx, q.client.UniversalClient, q.cfg.Stream, map[string]interface{}{
+		fieldPayload: data,
+		fieldRetry:   0,
+	})
+}
+
+func (q *Queue[T]) add(ctx context.Context, c redis.Cmdable, stream string, values map[string]interface{}) (string, error) {
+	args := &redis.XAddArgs{Stream: stream, Values: values}
+	if q.cfg.MaxLen > 0 {
+		args.MaxLen = q.cfg.MaxLen
+		args.Approx = true
+	}
+	return c.XAdd(ctx, args).Result()
+}
+
+// Run 启动消费，阻塞直到 ctx 结束；结束时停止拉取新消息并等待处理中的消息完成
+func (q *Queue[T]) Run(ctx context.Context, handler Handler[T]) error {
+	jobs := make(chan redis.XMessage)
+	// 处理中的消息不随 ctx 取消，保证优雅退出
+	handlerCtx := context.WithoutCancel(ctx)
+
+	var wg sync.WaitGroup
+	for i := 0; i < q.cfg.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for msg := range jobs {
+				q.process(handlerCtx, msg, handler)
+			}
+		}()
+	}
+
+	err := q.fetch(ctx, jobs)
+	close(jobs)
+	wg.Wait()
+	return err
+}
+
+// fetch 拉取消息：先处理本消费者未确认的消息，再读取新消息并定期认领超时消息
+func (q *Queue[T]) fetch(ctx context.Context, jobs chan<- redis.XMessage) error {
+	dispatch := func(msgs []redis.XMessage) bool {
+		for _, msg := range msgs {
+			select {
+			case jobs <- msg:
+			case <-ctx.Done():
+				return false
+			}
+		}
+		return true
+	}
+
+	// 进程重启后先处理上次未确认的消息
+	pendingID := "0"
+	lastClaim := time.Now()
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if time.Since(lastClaim) >= q.cfg.ClaimInterval {
+			lastClaim = time.Now()
+			if !dispatch(q.claim(ctx)) {
+				return nil
+			}
+		}
+
+		id := ">"
+		if pendingID != "" {
+			id = pendingID
+		}
+		streams, err := q.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    q.cfg.Group,
+			Consumer: q.cfg.Consumer,
+			Streams:  []string{q.cfg.Stream, id},
+			Count:    int64(q.cfg.Concurrency),
+			Block:    q.cfg.BlockTimeout,
+		}).Result()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if errors.Is(err, redis.Nil) {
+				continue
+			}
+			log.GetLogger().Warnf("queue %s read failed: %v", q.cfg.Stream, err)
+			if !sleepCtx(ctx, time.Second) {
+				return nil
+			}
+			continue
+		}
+
+		var msgs []redis.XMessage
+		for _, s := range streams {
+			msgs = append(msgs, s.Messages...)
+		}
+		if pendingID != "" {
+			// 未确认的消息已全部读取
+			if len(msgs) == 0 {
+				pendingID = ""
+				continue
+			}
+			pendingID = msgs[len(msgs)-1].ID
+		}
+		if !dispatch(msgs) {
+			return nil
+		}
+	}
+}
+
+// claim 认领其他消费者超时未确认的消息(消费者崩溃等情况)
+func (q *Queue[T]) claim(ctx context.Context) []redis.XMessage {
+	var claimed []redis.XMessage
+	start := "0-0"
+	for {
+		msgs, next, err := q.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   q.cfg.Stream,
+			Group:    q.cfg.Group,
+			Consumer: q.cfg.Consumer,
+			MinIdle:  q.cfg.ClaimMinIdle,
+			Start:    start,
+			Count:    100,
+		}).Result()
+		if err != nil {
+			if ctx.Err() == nil {
+				log.GetLogger().Warnf("queue %s autoclaim failed: %v", q.cfg.Stream, err)
+			}
+			return claimed
+		}
+		claimed = append(claimed, msgs...)
+		if next == "0-0" || next == "" {
+			return claimed
+		}
+		start = next
+	}
+}
+
+// process 处理单条消息，失败时带重试次数重新投递或转入死信队列
+func (q *Queue[T]) process(ctx context.Context, raw redis.XMessage, handler Handler[T]) {
+	msg := &Message[T]{ID: raw.ID}
+	if v, ok := raw.Values[fieldRetry].(string); ok {
+		msg.Retry, _ = strconv.Atoi(v)
+	}
+	payload, _ := raw.Values[fieldPayload].(string)
+
+	var err error
+	if decodeErr := q.client.DecodeModel([]byte(payload), &msg.Payload); decodeErr != nil {
+		// 无法解码的消息重试也不会成功，直接进入死信队列
+		msg.Retry = q.cfg.MaxRetries
+		err = fmt.Errorf("decode payload: %w", decodeErr)
+	} else {
+		err = safeHandle(ctx, handler, msg)
+	}
+
+	if err == nil {
+		if ackErr := q.client.XAck(ctx, q.cfg.Stream, q.cfg.Group, raw.ID).Err(); ackErr != nil {
+			log.GetLogger().Warnf("queue %s ack %s failed: %v", q.cfg.Stream, raw.ID, ackErr)
+		}
+		return
+	}
+
+	_, pipeErr := q.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		if msg.Retry >= q.cfg.MaxRetries {
+			log.GetLogger().Warnf("queue %s message %s moved to dead letter after %d retries: %v",
+				q.cfg.Stream, raw.ID, msg.Retry, err)
+			_, _ = q.add(ctx, pipe, q.cfg.DeadLetterStream, map[string]interface{}{
+				fieldPayload: payload,
+				fieldRetry:   msg.Retry,
+				fieldError:   err.Error(),
+				fieldSource:  raw.ID,
+			})
+		} else {
+			_, _ = q.add(ctx, pipe, q.cfg.Stream, map[string]interface{}{
+				fieldPayload: payload,
+				fieldRetry:   msg.Retry + 1,
+			})
+		}
+		pipe.XAck(ctx, q.cfg.Stream, q.cfg.Group, raw.ID)
+		return nil
+	})
+	if pipeErr != nil {
+		// 未确认的消息会在 ClaimMinIdle 后被重新认领
+		log.GetLogger().Warnf("queue %s requeue %s failed: %v", q.cfg.Stream, raw.ID, pipeErr)
+	}
+}
+
+func safeHandle[T any](ctx context.Context, handler Handler[T], msg *Message[T]) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panic: %v", r)
+		}
+	}()
+	return handler(ctx, msg)
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,233 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	kitredis "github.com/tiamxu/kit/redis"
+)
+
+type job struct {
+	ID int `json:"id"`
+}
+
+func newTestQueue(t *testing.T, cfg Config) (*Queue[job], *kitredis.RedisClient) {
+	t.Helper()
+	s := miniredis.RunT(t)
+	client, err := kitredis.NewClient(&kitredis.Config{Address: s.Addr()})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	if cfg.Stream == "" {
+		cfg.Stream = "jobs"
+	}
+	if cfg.Group == "" {
+		cfg.Group = "workers"
+	}
+	if cfg.Consumer == "" {
+		cfg.Consumer = "worker-1"
+	}
+	cfg.BlockTimeout = 20 * time.Millisecond
+	q, err := New[job](client, cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return q, client
+}
+
+// runQueue 后台运行队列，测试结束时停止并等待退出
+func runQueue(t *testing.T, q *Queue[job], handler Handler[job]) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- q.Run(ctx, handler) }()
+	t.Cleanup(func() {
+		cancel()
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Errorf("Run: %v", err)
+			}
+		case <-time.After(2 * time.Second):
+			t.Error("Run did not return after cancel")
+		}
+	})
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before deadline")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// recorder 记录处理过的消息
+type recorder struct {
+	mu   sync.Mutex
+	msgs []Message[job]
+}
+
+func (r *recorder) add(msg *Message[job]) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.msgs = append(r.msgs, *msg)
+}
+
+func (r *recorder) len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.msgs)
+}
+
+func pending(t *testing.T, c *kitredis.RedisClient, q *Queue[job]) int64 {
+	t.Helper()
+	p, err := c.XPending(context.Background(), q.cfg.Stream, q.cfg.Group).Result()
+	if err != nil {
+		t.Fatalf("XPending: %v", err)
+	}
+	return p.Count
+}
+
+func TestQueueProcess(t *testing.T) {
+	q, c := newTestQueue(t, Config{Concurrency: 2})
+	ctx := context.Background()
+	for i := 1; i <= 3; i++ {
+		if _, err := q.Enqueue(ctx, job{ID: i}); err != nil {
+			t.Fatalf("Enqueue: %v", err)
+		}
+	}
+
+	var rec recorder
+	runQueue(t, q, func(ctx context.Context, msg *Message[job]) error {
+		rec.add(msg)
+		return nil
+	})
+	waitFor(t, func() bool { return rec.len() == 3 })
+	waitFor(t, func() bool { return pending(t, c, q) == 0 })
+}
+
+// 处理失败时带重试次数重新投递，超过 MaxRetries 后转入死信队列
+func TestQueueDeadLetter(t *testing.T) {
+	q, c := newTestQueue(t, Config{MaxRetries: 2})
+	ctx := context.Background()
+	id, err := q.Enqueue(ctx, job{ID: 1})
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	var rec recorder
+	runQueue(t, q, func(ctx context.Context, msg *Message[job]) error {
+		rec.add(msg)
+		return errors.New("boom")
+	})
+	waitFor(t, func() bool { return c.XLen(ctx, q.cfg.DeadLetterStream).Val() == 1 })
+
+	rec.mu.Lock()
+	var retries []int
+	for _, m := range rec.msgs {
+		retries = append(retries, m.Retry)
+	}
+	rec.mu.Unlock()
+	if len(retries) != 3 || retries[0] != 0 || retries[1] != 1 || retries[2] != 2 {
+		t.Fatalf("handled with retries %v, want [0 1 2]", retries)
+	}
+
+	dead, err := c.XRange(ctx, q.cfg.DeadLetterStream, "-", "+").Result()
+	if err != nil || len(dead) != 1 {
+		t.Fatalf("dead letters = %v, %v", dead, err)
+	}
+	v := dead[0].Values
+	if v[fieldError] != "boom" || v[fieldRetry] != "2" || v[fieldSource] == "" || v[fieldSource] == id {
+		t.Fatalf("dead letter values = %v", v)
+	}
+	waitFor(t, func() bool { return pending(t, c, q) == 0 })
+}
+
+// 无法解码的消息不重试，直接转入死信队列
+func TestQueueDecodeFailure(t *testing.T) {
+	q, c := newTestQueue(t, Config{MaxRetries: 3})
+	ctx := context.Background()
+	err := c.XAdd(ctx, &redis.XAddArgs{Stream: q.cfg.Stream, Values: map[string]interface{}{
+		fieldPayload: "not an envelope", fieldRetry: 0,
+	}}).Err()
+	if err != nil {
+		t.Fatalf("XAdd: %v", err)
+	}
+
+	var rec recorder
+	runQueue(t, q, func(ctx context.Context, msg *Message[job]) error {
+		rec.add(msg)
+		return nil
+	})
+	waitFor(t, func() bool { return c.XLen(ctx, q.cfg.DeadLetterStream).Val() == 1 })
+	if rec.len() != 0 {
+		t.Fatalf("handler called %d times for undecodable payload", rec.len())
+	}
+}
+
+// 其他消费者读取后未确认(崩溃)的消息超过 ClaimMinIdle 后被 XAUTOCLAIM 认领
+func TestQueueClaimsAbandonedMessages(t *testing.T) {
+	q, c := newTestQueue(t, Config{
+		Consumer:      "worker-2",
+		ClaimInterval: 50 * time.Millisecond,
+		ClaimMinIdle:  50 * time.Millisecond,
+	})
+	ctx := context.Background()
+	if _, err := q.Enqueue(ctx, job{ID: 7}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	// worker-1 读取后崩溃
+	read, err := c.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group: q.cfg.Group, Consumer: "worker-1", Streams: []string{q.cfg.Stream, ">"}, Count: 1,
+	}).Result()
+	if err != nil || len(read) != 1 || len(read[0].Messages) != 1 {
+		t.Fatalf("XReadGroup = %v, %v", read, err)
+	}
+
+	var rec recorder
+	runQueue(t, q, func(ctx context.Context, msg *Message[job]) error {
+		rec.add(msg)
+		return nil
+	})
+	waitFor(t, func() bool { return rec.len() == 1 })
+	rec.mu.Lock()
+	got := rec.msgs[0]
+	rec.mu.Unlock()
+	if got.ID != read[0].Messages[0].ID || got.Payload.ID != 7 {
+		t.Fatalf("claimed %+v, want message %s", got, read[0].Messages[0].ID)
+	}
+	waitFor(t, func() bool { return pending(t, c, q) == 0 })
+}
+
+// 重启后先处理本消费者上次未确认的消息
+func TestQueueResumesOwnPending(t *testing.T) {
+	q, c := newTestQueue(t, Config{})
+	ctx := context.Background()
+	if _, err := q.Enqueue(ctx, job{ID: 9}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	err := c.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group: q.cfg.Group, Consumer: q.cfg.Consumer, Streams: []string{q.cfg.Stream, ">"}, Count: 1,
+	}).Err()
+	if err != nil {
+		t.Fatalf("XReadGroup: %v", err)
+	}
+
+	var rec recorder
+	runQueue(t, q, func(ctx context.Context, msg *Message[job]) error {
+		rec.add(msg)
+		return nil
+	})
+	waitFor(t, func() bool { return rec.len() == 1 })
+	waitFor(t, func() bool { return pending(t, c, q) == 0 })
+}
@@ -0,0 +1,63 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSlidingWindowLimiter(t *testing.T) {
+	c, _ := newTestClient(t, nil)
+	ctx := context.Background()
+	l := c.NewSlidingWindowLimiter(3, time.Second)
+
+	for i := 0; i < 3; i++ {
+		res, err := l.Allow(ctx, "user:1")
+		if err != nil {
+			t.Fatalf("Allow: %v", err)
+		}
+		if !res.Allowed || res.Remaining != 2-i || res.Limit != 3 || res.RetryAfter != 0 {
+			t.Fatalf("request %d = %+v, want allowed with %d remaining", i, res, 2-i)
+		}
+	}
+	res, err := l.Allow(ctx, "user:1")
+	if err != nil {
+		t.Fatalf("Allow: %v", err)
+	}
+	if res.Allowed || res.Remaining != 0 || res.RetryAfter <= 0 || res.RetryAfter > time.Second {
+		t.Fatalf("4th request = %+v, want rejected with RetryAfter in (0, 1s]", res)
+	}
+	// 不同 key 额度独立
+	if res, err := l.Allow(ctx, "user:2"); err != nil || !res.Allowed {
+		t.Fatalf("other key = %+v, %v, want allowed", res, err)
+	}
+}
+
+func TestGCRALimiter(t *testing.T) {
+	c, _ := newTestClient(t, nil)
+	ctx := context.Background()
+	// 每秒10次，允许突发2次
+	l := c.NewGCRALimiter(10, time.Second, 2)
+
+	for i := 0; i < 2; i++ {
+		res, err := l.Allow(ctx, "api")
+		if err != nil {
+			t.Fatalf("Allow: %v", err)
+		}
+		if !res.Allowed || res.Limit != 2 || res.Remaining != 1-i {
+			t.Fatalf("request %d = %+v, want allowed with %d remaining", i, res, 1-i)
+		}
+	}
+	res, err := l.Allow(ctx, "api")
+	if err != nil {
+		t.Fatalf("Allow: %v", err)
+	}
+	if res.Allowed || res.RetryAfter <= 0 || res.RetryAfter > 100*time.Millisecond {
+		t.Fatalf("3rd request = %+v, want rejected with RetryAfter in (0, 100ms]", res)
+	}
+
+	time.Sleep(res.RetryAfter + 10*time.Millisecond)
+	if res, err := l.Allow(ctx, "api"); err != nil || !res.Allowed {
+		t.Fatalf("request after RetryAfter = %+v, %v, want allowed", res, err)
+	}
+}
@@ -1,18 +1,26 @@
 package redis
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+// 扩展方法不得与 go-redis 命令同名，保证 RedisClient 可作为 UniversalClient 使用
+var _ redis.UniversalClient = (*RedisClient)(nil)
+
 type RedisClient struct {
-	*redis.Client
-	config *Config
+	redis.UniversalClient
+	config       *Config
+	codecID      uint8
+	compressorID uint8
+
+	hook       *Hook
+	stopReport chan struct{}
+	closeOnce  sync.Once
 }
 
 // NewClient new redis client
@@ -37,32 +45,175 @@ func NewClient(cfg *Config) (*RedisClient, error) {
 	if cfg.GzipMinSize <= 0 {
 		cfg.GzipMinSize = 2048 // 提高默认阈值
 	}
-	option := &redis.Options{
-		Addr:         cfg.Address,
-		Password:     cfg.Password,
-		DB:           cfg.DB,
-		PoolSize:     cfg.PoolSize,
-		MinIdleConns: cfg.MinIdle,
-		MaxIdleConns: cfg.MaxIdle,
-		DialTimeout:  time.Duration(cfg.DialTimeout) * time.Second,
-		ReadTimeout:  time.Duration(cfg.Timeout) * time.Second, //从网络连接中读取数据超时时间
-		WriteTimeout: time.Duration(cfg.Timeout) * time.Second, //把数据写入网络连接的超时时间
+	if cfg.RetryTimes == 0 {
+		cfg.RetryTimes = defaultRetryTimes
+	}
+	if cfg.MinRetryBackoff <= 0 {
+		cfg.MinRetryBackoff = defaultMinRetryBackoff
+	}
+	if cfg.MaxRetryBackoff <= 0 {
+		cfg.MaxRetryBackoff = defaultMaxRetryBackoff
+	}
+	if cfg.Codec == "" {
+		cfg.Codec = "json"
+	}
+	if cfg.Compressor == "" {
+		cfg.Compressor = "gzip"
+	}
+	codecID, err := lookupCodecID(cfg.Codec)
+	if err != nil {
+		return nil, err
+	}
+	compressorID, err := lookupCompressorID(cfg.Compressor)
+	if err != nil {
+		return nil, err
 	}
 
-	client := redis.NewClient(option)
+	client, err := newUniversalClient(cfg)
+	if err != nil {
+		return nil, err
+	}
 
 	// 测试连接
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Timeout)*time.Second)
 	defer cancel()
 
 	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
 		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
-	return &RedisClient{
-		Client: client,
-		config: cfg,
-	}, nil
+	r := &RedisClient{
+		UniversalClient: client,
+		config:          cfg,
+		codecID:         codecID,
+		compressorID:    compressorID,
+	}
+	// 重试钩子最先添加，每次重试都会重新经过之后的钩子
+	client.AddHook(retryHook{policy: RetryPolicy{
+		MaxRetries: max(cfg.RetryTimes, 0),
+		MinBackoff: cfg.MinRetryBackoff,
+		MaxBackoff: cfg.MaxRetryBackoff,
+	}})
+	// 前缀钩子在重试钩子之后添加，之后的钩子看到的都是带前缀的 key
+	if cfg.KeyPrefix != "" {
+		client.AddHook(prefixHook{prefix: cfg.KeyPrefix})
+	}
+	if cfg.Stats != nil || cfg.TracerProvider != nil {
+		var statsCfg StatsConfig
+		if cfg.Stats != nil {
+			statsCfg = *cfg.Stats
+		}
+		r.hook = NewHook(statsCfg, cfg.TracerProvider)
+		client.AddHook(r.hook)
+	}
+	if cfg.Stats != nil {
+		interval := cfg.Stats.ReportInterval
+		if interval <= 0 {
+			interval = defaultReportInterval
+		}
+		r.stopReport = make(chan struct{})
+		go r.report(interval, r.stopReport)
+	}
+	return r, nil
+}
+
+// Close 停止统计上报并关闭客户端
+func (r *RedisClient) Close() error {
+	r.closeOnce.Do(func() {
+		if r.stopReport != nil {
+			close(r.stopReport)
+		}
+	})
+	return r.UniversalClient.Close()
+}
+
+// newUniversalClient 根据部署模式创建客户端
+func newUniversalClient(cfg *Config) (redis.UniversalClient, error) {
+	dialTimeout := time.Duration(cfg.DialTimeout) * time.Second
+	timeout := time.Duration(cfg.Timeout) * time.Second //读写超时时间
+	tlsConfig, err := buildTLSConfig(cfg.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	switch cfg.Mode {
+	case ModeSingle, "":
+		return redis.NewClient(&redis.Options{
+			Addr:                cfg.Address,
+			Username:            cfg.Username,
+			Password:            cfg.Password,
+			CredentialsProvider: cfg.CredentialsProvider,
+			DB:                  cfg.DB,
+			PoolSize:            cfg.PoolSize,
+			MinIdleConns:        cfg.MinIdle,
+			MaxIdleConns:        cfg.MaxIdle,
+			MaxRetries:          -1, // 由 retryHook 统一重试
+			DialTimeout:         dialTimeout,
+			ReadTimeout:         timeout, //从网络连接中读取数据超时时间
+			WriteTimeout:        timeout, //把数据写入网络连接的超时时间
+			TLSConfig:           tlsConfig,
+		}), nil
+	case ModeSentinel:
+		if cfg.MasterName == "" || len(cfg.SentinelAddrs) == 0 {
+			return nil, fmt.Errorf("sentinel mode requires master_name and sentinel_addrs")
+		}
+		option := &redis.FailoverOptions{
+			MasterName:       cfg.MasterName,
+			SentinelAddrs:    cfg.SentinelAddrs,
+			SentinelUsername: cfg.SentinelUsername,
+			SentinelPassword: cfg.SentinelPassword,
+			RouteByLatency:   cfg.RouteByLatency,
+			RouteRandomly:    cfg.RouteRandomly,
+			Username:         cfg.Username,
+			Password:         cfg.Password,
+			DB:               cfg.DB,
+			PoolSize:         cfg.PoolSize,
+			MinIdleConns:     cfg.MinIdle,
+			MaxIdleConns:     cfg.MaxIdle,
+			MaxRetries:       -1, // 由 retryHook 统一重试
+			DialTimeout:      dialTimeout,
+			ReadTimeout:      timeout,
+			WriteTimeout:     timeout,
+			TLSConfig:        tlsConfig,
+		}
+		// FailoverOptions 不支持 CredentialsProvider，创建后设置到数据节点的连接选项上(建连时读取)
+		// 读写分离时由 ClusterClient 将只读命令路由到从节点
+		if cfg.ReadOnly || cfg.RouteByLatency || cfg.RouteRandomly {
+			client := redis.NewFailoverClusterClient(option)
+			client.Options().CredentialsProvider = cfg.CredentialsProvider
+			return client, nil
+		}
+		client := redis.NewFailoverClient(option)
+		client.Options().CredentialsProvider = cfg.CredentialsProvider
+		return client, nil
+	case ModeCluster:
+		if len(cfg.ClusterAddrs) == 0 {
+			return nil, fmt.Errorf("cluster mode requires cluster_addrs")
+		}
+		if cfg.DB != 0 {
+			return nil, fmt.Errorf("cluster mode does not support db %d", cfg.DB)
+		}
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:               cfg.ClusterAddrs,
+			ReadOnly:            cfg.ReadOnly,
+			RouteByLatency:      cfg.RouteByLatency,
+			RouteRandomly:       cfg.RouteRandomly,
+			Username:            cfg.Username,
+			Password:            cfg.Password,
+			CredentialsProvider: cfg.CredentialsProvider,
+			PoolSize:            cfg.PoolSize,
+			MinIdleConns:        cfg.MinIdle,
+			MaxIdleConns:        cfg.MaxIdle,
+			MaxRetries:          -1, // 由 retryHook 统一重试
+			DialTimeout:         dialTimeout,
+			ReadTimeout:         timeout,
+			WriteTimeout:        timeout,
+			TLSConfig:           tlsConfig,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unsupported redis mode: %s", cfg.Mode)
+	}
 }
 
 // SetModelToCache save model to cache
@@ -76,48 +227,15 @@ func (r *RedisClient) SetModelToCache(ctx context.Context, key string, model int
 	}
 	defer cancel()
 
-	gziped, data, err := toGzipJSON(model, r.config.GzipMinSize)
+	value, err := r.EncodeModel(model)
 	if err != nil {
 		return fmt.Errorf("SetModelToCache[compress] key=%s: %w", key, err)
 	}
-	// 根据压缩情况设置标志位
-	var flag uint32
-	if gziped {
-		flag = CacheFormatJSONGzip
-	} else {
-		flag = CacheFormatJSON
-	}
 
-	cacheItem := modelCacheItem{
-		Data: data,
-		Flag: flag,
+	if err := r.Set(ctx, key, value, ttl).Err(); err != nil {
+		return fmt.Errorf("SetModelToCache key=%s: %w", key, err)
 	}
-
-	// 复用内存池
-	buf := bufferPool.Get().(*bytes.Buffer)
-	defer func() {
-		buf.Reset()
-		bufferPool.Put(buf)
-	}()
-
-	enc := json.NewEncoder(buf)
-	enc.SetEscapeHTML(false)
-	if err := enc.Encode(cacheItem); err != nil {
-		return fmt.Errorf("SetModelToCache[encode] key=%s: %w", key, err)
-	}
-
-	var lastErr error
-	for i := 0; i <= r.config.RetryTimes; i++ {
-		if ctx.Err() != nil {
-			return fmt.Errorf("operation canceled: %w", ctx.Err())
-		}
-		if _, err = r.Set(ctx, key, buf.Bytes(), ttl).Result(); err == nil {
-			return nil
-		}
-		lastErr = err
-		time.Sleep(time.Duration(i*100) * time.Millisecond) // 线性退避
-	}
-	return fmt.Errorf("SetModelToCache failed after %d retries: %w", r.config.RetryTimes, lastErr)
+	return nil
 }
 
 // GetCacheToModel get cache to model
@@ -134,52 +252,58 @@ func (r *RedisClient) GetCacheToModel(ctx context.Context, key string, model int
 	}
 	defer cancel()
 
-	// 带重试的读取
-	var (
-		value string
-		err   error
-	)
-	for i := 0; i <= r.config.RetryTimes; i++ {
-		if ctx.Err() != nil {
-			return false, fmt.Errorf("operation canceled: %w", ctx.Err())
-		}
-		if value, err = r.Get(ctx, key).Result(); err == nil {
-			break
-		}
-		if err == redis.Nil {
-			return false, nil
-		}
-		time.Sleep(time.Duration(i*100) * time.Millisecond)
+	value, err := r.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		r.hook.recordCache(0, 1)
+		return false, nil
 	}
 	if err != nil {
 		return false, fmt.Errorf("GetCacheToModel[key=%s]: %w", key, err)
 	}
-
-	// 优化解码流程
-	var cacheItem modelCacheItem
-	dec := json.NewDecoder(bytes.NewReader([]byte(value)))
-	dec.UseNumber() // 防止数值类型失真
-	if err := dec.Decode(&cacheItem); err != nil {
-		return false, fmt.Errorf("GetCacheToModel[decode_header] key=%s: %w", key, err)
+	if IsAbsent(value) {
+		r.hook.recordCache(0, 1)
+		return false, fmt.Errorf("GetCacheToModel[key=%s]: %w", key, ErrAbsent)
 	}
+	r.hook.recordCache(1, 0)
 
-	// 优化解压分支判断
-	switch cacheItem.Flag {
-	case CacheFormatJSON:
-		if err := json.Unmarshal(cacheItem.Data, model); err != nil {
-			return false, fmt.Errorf("GetCacheToModel[unmarshal] key=%s: %w", key, err)
-		}
-	case CacheFormatJSONGzip:
-		if err := fromGzipJSON(cacheItem.Data, model); err != nil {
-			return false, fmt.Errorf("GetCacheToModel[unzip] key=%s: %w", key, err)
-		}
-	default:
-		return false, fmt.Errorf("GetCacheToModel[invalid_flag] key=%s flag=%d", key, cacheItem.Flag)
+	if err := r.DecodeModel(value, model); err != nil {
+		return false, fmt.Errorf("GetCacheToModel[decode] key=%s: %w", key, err)
 	}
 
 	return true, nil
 }
 
+// SetAbsent 写入"已知不存在"标记(负缓存)，之后 GetCacheToModel 返回 ErrAbsent，用于防止缓存穿透
+func (r *RedisClient) SetAbsent(ctx context.Context, key string, ttl time.Duration) error {
+	if err := r.Set(ctx, key, absentValue, ttl).Err(); err != nil {
+		return fmt.Errorf("SetAbsent key=%s: %w", key, err)
+	}
+	return nil
+}
+
+// EncodeModel 按配置的序列化与压缩方式编码模型，格式与 SetModelToCache 写入的一致
+func (r *RedisClient) EncodeModel(model interface{}) ([]byte, error) {
+	// 标志位记录实际使用的序列化与压缩方式
+	flag, data, err := encodeModel(model, r.codecID, r.compressorID, r.config.GzipMinSize)
+	if err != nil {
+		return nil, err
+	}
+	return marshalEnvelope(flag, data), nil
+}
+
+// DecodeModel 解码 EncodeModel 的结果，兼容历史 JSON 格式
+func (r *RedisClient) DecodeModel(value []byte, model interface{}) error {
+	flag, data, err := unmarshalEnvelope(value)
+	if err != nil {
+		return fmt.Errorf("decode header: %w", err)
+	}
+	// 按标志位选择解压与反序列化方式
+	if err := decodeModel(flag, data, model); err != nil {
+		return fmt.Errorf("flag=%d: %w", flag, err)
+	}
+	return nil
+}
+
 func IsRedisNil(err error) bool {
 	return err == redis.Nil
 }
@@ -0,0 +1,97 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestNewUniversalClientModes(t *testing.T) {
+	creds := func() (string, string) { return "svc", "secret" }
+	tests := []struct {
+		name    string
+		cfg     Config
+		want    string
+		wantErr bool
+	}{
+		{"default", Config{Address: "127.0.0.1:6379"}, "*redis.Client", false},
+		{"single", Config{Mode: ModeSingle, Address: "127.0.0.1:6379"}, "*redis.Client", false},
+		{"sentinel", Config{Mode: ModeSentinel, MasterName: "mymaster", SentinelAddrs: []string{"127.0.0.1:26379"},
+			CredentialsProvider: creds}, "*redis.Client", false},
+		{"sentinel read only", Config{Mode: ModeSentinel, MasterName: "mymaster", SentinelAddrs: []string{"127.0.0.1:26379"},
+			ReadOnly: true, CredentialsProvider: creds}, "*redis.ClusterClient", false},
+		{"sentinel without master", Config{Mode: ModeSentinel, SentinelAddrs: []string{"127.0.0.1:26379"}}, "", true},
+		{"sentinel without addrs", Config{Mode: ModeSentinel, MasterName: "mymaster"}, "", true},
+		{"cluster", Config{Mode: ModeCluster, ClusterAddrs: []string{"127.0.0.1:7000"}}, "*redis.ClusterClient", false},
+		{"cluster without addrs", Config{Mode: ModeCluster}, "", true},
+		{"cluster with db", Config{Mode: ModeCluster, ClusterAddrs: []string{"127.0.0.1:7000"}, DB: 1}, "", true},
+		{"unknown", Config{Mode: "proxy"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := newUniversalClient(&tt.cfg)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("newUniversalClient succeeded, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newUniversalClient: %v", err)
+			}
+			defer c.Close()
+			if got := fmt.Sprintf("%T", c); got != tt.want {
+				t.Fatalf("client type = %s, want %s", got, tt.want)
+			}
+			// go-redis 内置重试关闭(-1，部分客户端归一化为0)，统一由 retryHook 处理
+			switch c := c.(type) {
+			case *redis.Client:
+				if opt := c.Options(); opt.MaxRetries > 0 || (tt.cfg.CredentialsProvider != nil && opt.CredentialsProvider == nil) {
+					t.Fatalf("options MaxRetries=%d CredentialsProvider set=%v", opt.MaxRetries, opt.CredentialsProvider != nil)
+				}
+			case *redis.ClusterClient:
+				if opt := c.Options(); opt.MaxRetries > 0 || (tt.cfg.CredentialsProvider != nil && opt.CredentialsProvider == nil) {
+					t.Fatalf("options MaxRetries=%d CredentialsProvider set=%v", opt.MaxRetries, opt.CredentialsProvider != nil)
+				}
+			}
+		})
+	}
+}
+
+func TestNewClientCluster(t *testing.T) {
+	s := miniredis.RunT(t)
+	c, err := NewClient(&Config{Mode: ModeCluster, ClusterAddrs: []string{s.Addr()}})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer c.Close()
+	if _, ok := c.UniversalClient.(*redis.ClusterClient); !ok {
+		t.Fatalf("client type = %T, want *redis.ClusterClient", c.UniversalClient)
+	}
+
+	ctx := context.Background()
+	model := struct{ Name string }{"cluster"}
+	if err := c.SetModelToCache(ctx, "k", model, 0); err != nil {
+		t.Fatalf("SetModelToCache: %v", err)
+	}
+	var got struct{ Name string }
+	if ok, err := c.GetCacheToModel(ctx, "k", &got); err != nil || !ok || got != model {
+		t.Fatalf("GetCacheToModel = %v, %v, %+v", ok, err, got)
+	}
+}
+
+func TestNewClientInvalidConfig(t *testing.T) {
+	for _, cfg := range []*Config{
+		{Mode: ModeCluster},
+		{Codec: "xml"},
+		{Compressor: "lz4"},
+	} {
+		if c, err := NewClient(cfg); err == nil {
+			_ = c.Close()
+			t.Errorf("NewClient(%+v) succeeded, want error", cfg)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// 与 go-redis 的默认重试次数一致
+	defaultRetryTimes      = 3
+	defaultMinRetryBackoff = 8 * time.Millisecond
+	defaultMaxRetryBackoff = 512 * time.Millisecond
+)
+
+// 可重试的服务端错误前缀: 数据加载中、主从切换、集群迁移等临时状态
+var retryableErrorPrefixes = []string{
+	"LOADING",
+	"READONLY",
+	"MOVED",
+	"ASK",
+	"TRYAGAIN",
+	"CLUSTERDOWN",
+	"MASTERDOWN",
+}
+
+// RetryPolicy 重试策略，指数退避并带随机抖动
+// 超时、连接中断时命令可能已在服务端执行，默认仍会重试；
+// 非幂等操作(加锁、限流计数等)需用 WithNonIdempotent 标记 ctx，此时只在确定未执行时重试
+type RetryPolicy struct {
+	// MaxRetries 最大重试次数，0 表示不重试
+	MaxRetries int
+	// MinBackoff 首次重试前的等待时间
+	MinBackoff time.Duration
+	// MaxBackoff 单次等待时间上限
+	MaxBackoff time.Duration
+}
+
+// Backoff 返回第 attempt 次重试(从0开始)前的等待时间，取 [d/2, d) 区间内的随机值
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	minBackoff, maxBackoff := p.MinBackoff, p.MaxBackoff
+	if minBackoff <= 0 {
+		minBackoff = defaultMinRetryBackoff
+	}
+	if maxBackoff < minBackoff {
+		maxBackoff = minBackoff
+	}
+	d := minBackoff
+	for i := 0; i < attempt && d < maxBackoff; i++ {
+		d *= 2
+	}
+	if d > maxBackoff {
+		d = maxBackoff
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)+1))
+}
+
+// Do 执行 fn，遇到可重试错误时按策略退避后重试，等待期间响应 ctx 取消
+func (p RetryPolicy) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = fn(ctx); err == nil || attempt >= p.MaxRetries || !retryable(ctx, err) {
+			return err
+		}
+		if waitErr := wait(ctx, p.Backoff(attempt)); waitErr != nil {
+			return errors.Join(err, waitErr)
+		}
+	}
+}
+
+// retryHook 按 RetryPolicy 重试所有命令与 pipeline，go-redis 内置重试已关闭(MaxRetries=-1)，避免两层重试叠加
+type retryHook struct {
+	policy RetryPolicy
+}
+
+func (h retryHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h retryHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		return h.policy.Do(ctx, func(ctx context.Context) error {
+			return next(ctx, cmd)
+		})
+	}
+}
+
+func (h retryHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		return h.policy.Do(ctx, func(ctx context.Context) error {
+			return next(ctx, cmds)
+		})
+	}
+}
+
+type nonIdempotentKey struct{}
+
+// WithNonIdempotent 标记 ctx 中执行的命令不可重复执行，RetryPolicy 只对建立连接失败与服务端拒绝执行的错误重试
+func WithNonIdempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, nonIdempotentKey{}, true)
+}
+
+func retryable(ctx context.Context, err error) bool {
+	if nonIdempotent, _ := ctx.Value(nonIdempotentKey{}).(bool); nonIdempotent {
+		return isDialError(err) || isRejected(err)
+	}
+	return IsRetryable(err)
+}
+
+// isDialError 建立连接失败，命令未发出
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.Is(err, syscall.ECONNREFUSED) || errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// isRejected 服务端因临时状态拒绝执行
+func isRejected(err error) bool {
+	for _, prefix := range retryableErrorPrefixes {
+		if redis.HasErrorPrefix(err, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRetryable 判断错误是否为临时性错误(网络异常、节点加载中、主从切换、集群重定向等)
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, redis.Nil) || errors.Is(err, redis.ErrClosed) {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return isRejected(err)
+}
+
+// wait 等待 d，ctx 结束时提前返回
+func wait(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,263 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestClient(t *testing.T, cfg *Config) (*RedisClient, *miniredis.Miniredis) {
+	t.Helper()
+	s := miniredis.RunT(t)
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	cfg.Address = s.Addr()
+	c, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c, s
+}
+
+// serverError 通过 miniredis 获取真实的服务端错误
+func serverError(t *testing.T, msg string) error {
+	t.Helper()
+	s := miniredis.RunT(t)
+	c := redis.NewClient(&redis.Options{Addr: s.Addr(), MaxRetries: -1})
+	defer c.Close()
+	s.SetError(msg)
+	err := c.Get(context.Background(), "k").Err()
+	if err == nil {
+		t.Fatalf("expected server error %q", msg)
+	}
+	return err
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"LOADING", serverError(t, "LOADING Redis is loading the dataset in memory"), true},
+		{"READONLY", serverError(t, "READONLY You can't write against a read only replica."), true},
+		{"MOVED", serverError(t, "MOVED 3999 127.0.0.1:6381"), true},
+		{"ASK", serverError(t, "ASK 3999 127.0.0.1:6381"), true},
+		{"CLUSTERDOWN", serverError(t, "CLUSTERDOWN The cluster is down"), true},
+		{"WRONGTYPE", serverError(t, "WRONGTYPE Operation against a key holding the wrong kind of value"), false},
+		{"net.OpError", &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}, true},
+		{"wrapped ECONNRESET", fmt.Errorf("read: %w", syscall.ECONNRESET), true},
+		{"EOF", io.EOF, true},
+		{"redis.Nil", redis.Nil, false},
+		{"client closed", redis.ErrClosed, false},
+		{"context canceled", context.Canceled, false},
+		{"deadline exceeded", fmt.Errorf("get: %w", context.DeadlineExceeded), false},
+		{"other", errors.New("boom"), false},
+	}
+	for _, tt := range tests {
+		if got := IsRetryable(tt.err); got != tt.want {
+			t.Errorf("IsRetryable(%s: %v) = %v, want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{MinBackoff: 8 * time.Millisecond, MaxBackoff: 64 * time.Millisecond}
+	for attempt, d := range []time.Duration{8, 16, 32, 64, 64} {
+		d *= time.Millisecond
+		for i := 0; i < 20; i++ {
+			if got := p.Backoff(attempt); got < d/2 || got > d {
+				t.Fatalf("Backoff(%d) = %s, want in [%s, %s]", attempt, got, d/2, d)
+			}
+		}
+	}
+}
+
+func TestRetryPolicyDo(t *testing.T) {
+	s := miniredis.RunT(t)
+	c := redis.NewClient(&redis.Options{Addr: s.Addr(), MaxRetries: -1})
+	defer c.Close()
+	ctx := context.Background()
+	p := RetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+
+	t.Run("retry until success", func(t *testing.T) {
+		s.SetError("LOADING Redis is loading the dataset in memory")
+		attempts := 0
+		err := p.Do(ctx, func(ctx context.Context) error {
+			if attempts++; attempts == 3 {
+				s.SetError("")
+			}
+			return c.Set(ctx, "k", "v", 0).Err()
+		})
+		// 第3次调用前清除错误，因此第3次成功
+		if err != nil || attempts != 3 {
+			t.Fatalf("Do = %v after %d attempts, want success after 3", err, attempts)
+		}
+	})
+
+	t.Run("give up after max retries", func(t *testing.T) {
+		s.SetError("READONLY You can't write against a read only replica.")
+		defer s.SetError("")
+		attempts := 0
+		err := p.Do(ctx, func(ctx context.Context) error {
+			attempts++
+			return c.Set(ctx, "k", "v", 0).Err()
+		})
+		if !redis.HasErrorPrefix(err, "READONLY") || attempts != p.MaxRetries+1 {
+			t.Fatalf("Do = %v after %d attempts, want READONLY after %d", err, attempts, p.MaxRetries+1)
+		}
+	})
+
+	t.Run("no retry on non-retryable error", func(t *testing.T) {
+		if err := c.LPush(ctx, "list", "a").Err(); err != nil {
+			t.Fatal(err)
+		}
+		attempts := 0
+		err := p.Do(ctx, func(ctx context.Context) error {
+			attempts++
+			return c.Get(ctx, "list").Err()
+		})
+		if !redis.HasErrorPrefix(err, "WRONGTYPE") || attempts != 1 {
+			t.Fatalf("Do = %v after %d attempts, want WRONGTYPE after 1", err, attempts)
+		}
+
+		attempts = 0
+		err = p.Do(ctx, func(ctx context.Context) error {
+			attempts++
+			return c.Get(ctx, "missing").Err()
+		})
+		if !errors.Is(err, redis.Nil) || attempts != 1 {
+			t.Fatalf("Do = %v after %d attempts, want redis.Nil after 1", err, attempts)
+		}
+	})
+
+	t.Run("cancel during backoff", func(t *testing.T) {
+		s.SetError("LOADING Redis is loading the dataset in memory")
+		defer s.SetError("")
+		slow := RetryPolicy{MaxRetries: 5, MinBackoff: 10 * time.Second, MaxBackoff: 10 * time.Second}
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		time.AfterFunc(20*time.Millisecond, cancel)
+
+		attempts := 0
+		start := time.Now()
+		err := slow.Do(ctx, func(ctx context.Context) error {
+			attempts++
+			return c.Get(ctx, "k").Err()
+		})
+		if !errors.Is(err, context.Canceled) || !redis.HasErrorPrefix(err, "LOADING") {
+			t.Fatalf("Do = %v, want LOADING joined with context.Canceled", err)
+		}
+		if attempts != 1 || time.Since(start) > time.Second {
+			t.Fatalf("Do returned after %d attempts in %s, want 1 attempt and prompt return", attempts, time.Since(start))
+		}
+	})
+}
+
+// countHook 统计实际发送到连接上的命令次数
+type countHook struct{ n *int }
+
+func (h countHook) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (h countHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		*h.n++
+		return next(ctx, cmd)
+	}
+}
+
+func (h countHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+// 客户端只有 retryHook 一层重试，单次调用最多执行 RetryTimes+1 次
+func TestClientRetryTimes(t *testing.T) {
+	c, s := newTestClient(t, &Config{RetryTimes: 2, MinRetryBackoff: time.Millisecond, MaxRetryBackoff: time.Millisecond})
+	ctx := context.Background()
+	var n int
+	c.AddHook(countHook{n: &n})
+
+	s.SetError("LOADING Redis is loading the dataset in memory")
+	var m struct{ A int }
+	if _, err := c.GetCacheToModel(ctx, "k", &m); !redis.HasErrorPrefix(err, "LOADING") {
+		t.Fatalf("GetCacheToModel error = %v, want LOADING", err)
+	}
+	if n != 3 {
+		t.Fatalf("GET executed %d times, want 3", n)
+	}
+
+	s.SetError("")
+	n = 0
+	if err := c.PublishModel(ctx, "ch", m); err != nil {
+		t.Fatalf("PublishModel: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("PUBLISH executed %d times, want 1", n)
+	}
+}
+
+// 未配置 retry_times 时与 go-redis 默认一致重试3次，-1 关闭重试
+func TestClientRetryTimesDefault(t *testing.T) {
+	for _, tt := range []struct {
+		retryTimes int
+		want       int
+	}{
+		{0, defaultRetryTimes + 1},
+		{-1, 1},
+	} {
+		c, s := newTestClient(t, &Config{RetryTimes: tt.retryTimes, MinRetryBackoff: time.Millisecond, MaxRetryBackoff: time.Millisecond})
+		var n int
+		c.AddHook(countHook{n: &n})
+		s.SetError("LOADING Redis is loading the dataset in memory")
+		if err := c.Get(context.Background(), "k").Err(); !redis.HasErrorPrefix(err, "LOADING") {
+			t.Fatalf("retry_times=%d: Get error = %v, want LOADING", tt.retryTimes, err)
+		}
+		if n != tt.want {
+			t.Errorf("retry_times=%d: GET executed %d times, want %d", tt.retryTimes, n, tt.want)
+		}
+	}
+}
+
+// 非幂等命令超时或连接中断时可能已执行，不重试；确定未执行时仍重试
+func TestRetryPolicyNonIdempotent(t *testing.T) {
+	p := RetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	timeout := &net.OpError{Op: "read", Net: "tcp", Err: os.ErrDeadlineExceeded}
+	tests := []struct {
+		name          string
+		err           error
+		nonIdempotent bool
+		want          int
+	}{
+		{"timeout", timeout, false, 4},
+		{"timeout non-idempotent", timeout, true, 1},
+		{"EOF non-idempotent", io.EOF, true, 1},
+		{"dial non-idempotent", &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}, true, 4},
+		{"LOADING non-idempotent", serverError(t, "LOADING Redis is loading the dataset in memory"), true, 4},
+	}
+	for _, tt := range tests {
+		ctx := context.Background()
+		if tt.nonIdempotent {
+			ctx = WithNonIdempotent(ctx)
+		}
+		attempts := 0
+		_ = p.Do(ctx, func(ctx context.Context) error {
+			attempts++
+			return tt.err
+		})
+		if attempts != tt.want {
+			t.Errorf("%s: %d attempts, want %d", tt.name, attempts, tt.want)
+		}
+	}
+}
@@ -0,0 +1,324 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/time/rate"
+)
+
+const (
+	defaultScanCount    = 500
+	defaultTTLAuditSize = 100
+)
+
+// ScanOptions 批量扫描配置
+type ScanOptions struct {
+	// Count 每次 SCAN 的 COUNT 提示值，默认500
+	Count int64
+	// Type 仅处理指定类型的 key(string、hash、list、set、zset、stream)，默认全部
+	Type string
+	// RateLimit 每秒最多处理的 key 数(所有节点合计)，0 表示不限制
+	RateLimit int
+	// DryRun 仅统计匹配的 key，不执行删除(DeleteByPattern 有效)
+	DryRun bool
+	// SampleSize TTLAudit 报告中保留的无过期时间 key 样本数，默认100
+	SampleSize int
+}
+
+// ExportedKey ExportKeys 输出的单行记录
+type ExportedKey struct {
+	Key  string `json:"key"`
+	Type string `json:"type"`
+	// TTL 剩余过期时间(毫秒)，-1 表示未设置过期时间
+	TTL int64 `json:"ttl_ms"`
+	// Value string 类型为 []byte(JSON 中为 base64)，hash 为 map，list/set 为数组，zset 为成员与分数数组
+	Value interface{} `json:"value,omitempty"`
+}
+
+// TTLReport TTL 审计报告
+type TTLReport struct {
+	// Scanned 扫描的 key 总数
+	Scanned int64
+	// NoTTL 未设置过期时间的 key 数
+	NoTTL int64
+	// NoTTLSamples 未设置过期时间的 key 样本
+	NoTTLSamples []string
+	// Buckets 按剩余过期时间分布: <1m、<1h、<1d、<7d、>=7d
+	Buckets map[string]int64
+}
+
+var ttlBuckets = []struct {
+	name  string
+	upper time.Duration
+}{
+	{"<1m", time.Minute},
+	{"<1h", time.Hour},
+	{"<1d", 24 * time.Hour},
+	{"<7d", 7 * 24 * time.Hour},
+}
+
+// DeleteByPattern 按模式删除 key(SCAN + UNLINK)，返回删除(DryRun 时为匹配)的数量
+// 集群模式下遍历所有主节点
+func (r *RedisClient) DeleteByPattern(ctx context.Context, pattern string, opts ScanOptions) (int64, error) {
+	if pattern == "" {
+		return 0, fmt.Errorf("DeleteByPattern: pattern cannot be empty")
+	}
+	var deleted atomic.Int64
+	err := r.scanKeys(ctx, pattern, opts, func(ctx context.Context, c redis.Cmdable, prefix string, keys []string) error {
+		if opts.DryRun {
+			deleted.Add(int64(len(keys)))
+			return nil
+		}
+		// 逐个 UNLINK，避免集群模式下跨 slot 错误
+		cmds, err := c.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, key := range keys {
+				pipe.Unlink(ctx, key)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, cmd := range cmds {
+			deleted.Add(cmd.(*redis.IntCmd).Val())
+		}
+		return nil
+	})
+	if err != nil {
+		return deleted.Load(), fmt.Errorf("DeleteByPattern pattern=%s: %w", pattern, err)
+	}
+	return deleted.Load(), nil
+}
+
+// ExportKeys 将匹配的 key 及其值以 JSONL 格式写入 w，返回导出的数量
+// 大 key 会被完整读取，请配合 Type 与 RateLimit 使用
+func (r *RedisClient) ExportKeys(ctx context.Context, pattern string, w io.Writer, opts ScanOptions) (int64, error) {
+	var (
+		mu       sync.Mutex
+		exported atomic.Int64
+		enc      = json.NewEncoder(w)
+	)
+	err := r.scanKeys(ctx, pattern, opts, func(ctx context.Context, c redis.Cmdable, prefix string, keys []string) error {
+		items, err := exportBatch(ctx, c, keys)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, item := range items {
+			item.Key = strings.TrimPrefix(item.Key, prefix)
+			if err := enc.Encode(item); err != nil {
+				return err
+			}
+			exported.Add(1)
+		}
+		return nil
+	})
+	if err != nil {
+		return exported.Load(), fmt.Errorf("ExportKeys pattern=%s: %w", pattern, err)
+	}
+	return exported.Load(), nil
+}
+
+// TTLAudit 统计匹配 key 的过期时间分布，找出未设置过期时间的 key
+func (r *RedisClient) TTLAudit(ctx context.Context, pattern string, opts ScanOptions) (*TTLReport, error) {
+	if opts.SampleSize <= 0 {
+		opts.SampleSize = defaultTTLAuditSize
+	}
+	var mu sync.Mutex
+	report := &TTLReport{Buckets: make(map[string]int64)}
+	err := r.scanKeys(ctx, pattern, opts, func(ctx context.Context, c redis.Cmdable, prefix string, keys []string) error {
+		cmds, err := c.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, key := range keys {
+				pipe.PTTL(ctx, key)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		for i, cmd := range cmds {
+			ttl := cmd.(*redis.DurationCmd).Val()
+			switch {
+			case ttl == -2:
+				// 扫描期间已被删除
+				continue
+			case ttl < 0:
+				report.NoTTL++
+				if len(report.NoTTLSamples) < opts.SampleSize {
+					report.NoTTLSamples = append(report.NoTTLSamples, strings.TrimPrefix(keys[i], prefix))
+				}
+			default:
+				report.Buckets[ttlBucket(ttl)]++
+			}
+			report.Scanned++
+		}
+		return nil
+	})
+	if err != nil {
+		return report, fmt.Errorf("TTLAudit pattern=%s: %w", pattern, err)
+	}
+	return report, nil
+}
+
+// scanBatchFunc 处理一批 key，c 为执行命令的客户端，keys 已带上 prefix
+type scanBatchFunc func(ctx context.Context, c redis.Cmdable, prefix string, keys []string) error
+
+// scanKeys 在所有节点上 SCAN 匹配的 key，按批交给 fn 处理
+// 集群模式下节点客户端不经过前缀钩子，需手动添加前缀
+func (r *RedisClient) scanKeys(ctx context.Context, pattern string, opts ScanOptions, fn scanBatchFunc) error {
+	if pattern == "" {
+		pattern = "*"
+	}
+	if opts.Count <= 0 {
+		opts.Count = defaultScanCount
+	}
+	var limiter *rate.Limiter
+	if opts.RateLimit > 0 {
+		limiter = rate.NewLimiter(rate.Limit(opts.RateLimit), opts.RateLimit)
+	}
+
+	if cluster, ok := r.UniversalClient.(*redis.ClusterClient); ok {
+		prefix := r.config.KeyPrefix
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			return scanNode(ctx, node, prefix, prefix+pattern, opts, limiter, fn)
+		})
+	}
+	return scanNode(ctx, r.UniversalClient, "", pattern, opts, limiter, fn)
+}
+
+func scanNode(ctx context.Context, c redis.Cmdable, prefix, pattern string, opts ScanOptions,
+	limiter *rate.Limiter, fn scanBatchFunc) error {
+	var cursor uint64
+	for {
+		var (
+			keys []string
+			err  error
+		)
+		if opts.Type != "" {
+			keys, cursor, err = c.ScanType(ctx, cursor, pattern, opts.Count, opts.Type).Result()
+		} else {
+			keys, cursor, err = c.Scan(ctx, cursor, pattern, opts.Count).Result()
+		}
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			if err := waitN(ctx, limiter, len(keys)); err != nil {
+				return err
+			}
+			if err := fn(ctx, c, prefix, keys); err != nil {
+				return err
+			}
+		}
+		if cursor == 0 {
+			return nil
+		}
+	}
+}
+
+// waitN 按限流器等待 n 个配额，n 超过桶容量时分批等待
+func waitN(ctx context.Context, limiter *rate.Limiter, n int) error {
+	if limiter == nil {
+		return nil
+	}
+	for n > 0 {
+		m := n
+		if burst := limiter.Burst(); m > burst {
+			m = burst
+		}
+		if err := limiter.WaitN(ctx, m); err != nil {
+			return err
+		}
+		n -= m
+	}
+	return nil
+}
+
+// exportBatch 读取一批 key 的类型、过期时间与值
+func exportBatch(ctx context.Context, c redis.Cmdable, keys []string) ([]*ExportedKey, error) {
+	meta, err := c.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			pipe.Type(ctx, key)
+			pipe.PTTL(ctx, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*ExportedKey, 0, len(keys))
+	values := make([]redis.Cmder, 0, len(keys))
+	_, err = c.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			typ := meta[2*i].(*redis.StatusCmd).Val()
+			var cmd redis.Cmder
+			switch typ {
+			case "string":
+				cmd = pipe.Get(ctx, key)
+			case "hash":
+				cmd = pipe.HGetAll(ctx, key)
+			case "list":
+				cmd = pipe.LRange(ctx, key, 0, -1)
+			case "set":
+				cmd = pipe.SMembers(ctx, key)
+			case "zset":
+				cmd = pipe.ZRangeWithScores(ctx, key, 0, -1)
+			case "stream":
+				cmd = pipe.XRange(ctx, key, "-", "+")
+			case "none":
+				// 扫描期间已被删除
+				continue
+			}
+			ttl := meta[2*i+1].(*redis.DurationCmd).Val()
+			if ttl > 0 {
+				ttl = ttl / time.Millisecond
+			} else {
+				ttl = -1
+			}
+			items = append(items, &ExportedKey{Key: key, Type: typ, TTL: int64(ttl)})
+			values = append(values, cmd)
+		}
+		return nil
+	})
+	if err != nil && !IsRedisNil(err) {
+		return nil, err
+	}
+
+	for i, cmd := range values {
+		switch cmd := cmd.(type) {
+		case *redis.StringCmd:
+			items[i].Value, _ = cmd.Bytes()
+		case *redis.MapStringStringCmd:
+			items[i].Value = cmd.Val()
+		case *redis.StringSliceCmd:
+			items[i].Value = cmd.Val()
+		case *redis.ZSliceCmd:
+			items[i].Value = cmd.Val()
+		case *redis.XMessageSliceCmd:
+			items[i].Value = cmd.Val()
+		}
+	}
+	return items, nil
+}
+
+func ttlBucket(ttl time.Duration) string {
+	for _, b := range ttlBuckets {
+		if ttl < b.upper {
+			return b.name
+		}
+	}
+	return ">=7d"
+}
@@ -0,0 +1,187 @@
+package redis
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// seedScanKeys 通过客户端写入各类型的 key(经过前缀钩子)
+func seedScanKeys(t *testing.T, c *RedisClient) {
+	t.Helper()
+	ctx := context.Background()
+	_, err := c.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, "user:1", "alice", 30*time.Second)
+		pipe.HSet(ctx, "user:2", "name", "bob")
+		pipe.RPush(ctx, "user:3", "a", "b")
+		pipe.SAdd(ctx, "user:4", "x")
+		pipe.ZAdd(ctx, "user:5", redis.Z{Score: 1.5, Member: "m"})
+		pipe.XAdd(ctx, &redis.XAddArgs{Stream: "user:6", Values: map[string]interface{}{"f": "v"}})
+		pipe.Set(ctx, "order:1", "o", 2*time.Hour)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+}
+
+func scanConfigs(s *miniredis.Miniredis) map[string]*Config {
+	return map[string]*Config{
+		"single":         {Address: s.Addr()},
+		"single prefix":  {Address: s.Addr(), KeyPrefix: "app:"},
+		"cluster":        {Mode: ModeCluster, ClusterAddrs: []string{s.Addr()}},
+		"cluster prefix": {Mode: ModeCluster, ClusterAddrs: []string{s.Addr()}, KeyPrefix: "app:"},
+	}
+}
+
+func TestExportKeys(t *testing.T) {
+	s := miniredis.RunT(t)
+	for name, cfg := range scanConfigs(s) {
+		t.Run(name, func(t *testing.T) {
+			s.FlushAll()
+			// 其他前缀下的 key 不应被导出
+			s.Set("other:user:9", "x")
+			c, err := NewClient(cfg)
+			if err != nil {
+				t.Fatalf("NewClient: %v", err)
+			}
+			defer c.Close()
+			seedScanKeys(t, c)
+			ctx := context.Background()
+
+			var buf bytes.Buffer
+			n, err := c.ExportKeys(ctx, "user:*", &buf, ScanOptions{Count: 2})
+			if err != nil || n != 6 {
+				t.Fatalf("ExportKeys = %d, %v, want 6", n, err)
+			}
+			got := make(map[string]map[string]interface{})
+			sc := bufio.NewScanner(&buf)
+			for sc.Scan() {
+				var item map[string]interface{}
+				if err := json.Unmarshal(sc.Bytes(), &item); err != nil {
+					t.Fatalf("decode %s: %v", sc.Text(), err)
+				}
+				got[item["key"].(string)] = item
+			}
+			wantTypes := map[string]string{
+				"user:1": "string", "user:2": "hash", "user:3": "list",
+				"user:4": "set", "user:5": "zset", "user:6": "stream",
+			}
+			for key, typ := range wantTypes {
+				if item, ok := got[key]; !ok || item["type"] != typ {
+					t.Errorf("exported %s = %v, want type %s", key, item, typ)
+				}
+			}
+			// string 值为 base64，TTL 为毫秒
+			if v := got["user:1"]; v["value"] != "YWxpY2U=" || v["ttl_ms"].(float64) <= 0 || v["ttl_ms"].(float64) > 30000 {
+				t.Errorf("exported user:1 = %v", v)
+			}
+			if v := got["user:2"]; v["ttl_ms"].(float64) != -1 || v["value"].(map[string]interface{})["name"] != "bob" {
+				t.Errorf("exported user:2 = %v", v)
+			}
+
+			buf.Reset()
+			if n, err := c.ExportKeys(ctx, "user:*", &buf, ScanOptions{Type: "hash"}); err != nil || n != 1 {
+				t.Fatalf("ExportKeys(hash) = %d, %v, want 1", n, err)
+			}
+		})
+	}
+}
+
+func TestTTLAudit(t *testing.T) {
+	s := miniredis.RunT(t)
+	for name, cfg := range scanConfigs(s) {
+		t.Run(name, func(t *testing.T) {
+			s.FlushAll()
+			c, err := NewClient(cfg)
+			if err != nil {
+				t.Fatalf("NewClient: %v", err)
+			}
+			defer c.Close()
+			seedScanKeys(t, c)
+
+			report, err := c.TTLAudit(context.Background(), "", ScanOptions{SampleSize: 10})
+			if err != nil {
+				t.Fatalf("TTLAudit: %v", err)
+			}
+			sort.Strings(report.NoTTLSamples)
+			want := []string{"user:2", "user:3", "user:4", "user:5", "user:6"}
+			if report.Scanned != 7 || report.NoTTL != 5 || len(report.NoTTLSamples) != len(want) ||
+				report.Buckets["<1m"] != 1 || report.Buckets["<1d"] != 1 {
+				t.Fatalf("report = %+v", report)
+			}
+			for i, key := range want {
+				if report.NoTTLSamples[i] != key {
+					t.Fatalf("NoTTLSamples = %v, want %v", report.NoTTLSamples, want)
+				}
+			}
+
+			report, err = c.TTLAudit(context.Background(), "user:*", ScanOptions{SampleSize: 2})
+			if err != nil || report.Scanned != 6 || len(report.NoTTLSamples) != 2 {
+				t.Fatalf("TTLAudit(sample 2) = %+v, %v", report, err)
+			}
+		})
+	}
+}
+
+func TestDeleteByPattern(t *testing.T) {
+	s := miniredis.RunT(t)
+	for name, cfg := range scanConfigs(s) {
+		t.Run(name, func(t *testing.T) {
+			s.FlushAll()
+			s.Set("other:user:9", "x")
+			c, err := NewClient(cfg)
+			if err != nil {
+				t.Fatalf("NewClient: %v", err)
+			}
+			defer c.Close()
+			seedScanKeys(t, c)
+			ctx := context.Background()
+
+			if _, err := c.DeleteByPattern(ctx, "", ScanOptions{}); err == nil {
+				t.Fatal("DeleteByPattern with empty pattern succeeded")
+			}
+			if n, err := c.DeleteByPattern(ctx, "user:*", ScanOptions{DryRun: true}); err != nil || n != 6 {
+				t.Fatalf("DeleteByPattern(dry run) = %d, %v, want 6", n, err)
+			}
+			if len(s.Keys()) != 8 {
+				t.Fatalf("dry run deleted keys: %v", s.Keys())
+			}
+
+			n, err := c.DeleteByPattern(ctx, "user:*", ScanOptions{Count: 2, RateLimit: 20})
+			if err != nil || n != 6 {
+				t.Fatalf("DeleteByPattern = %d, %v, want 6", n, err)
+			}
+			keys := s.Keys()
+			sort.Strings(keys)
+			if len(keys) != 2 || keys[0] != cfg.KeyPrefix+"order:1" || keys[1] != "other:user:9" {
+				t.Fatalf("remaining keys = %v", keys)
+			}
+		})
+	}
+}
+
+// RateLimit 限制每秒处理的 key 数
+func TestScanRateLimit(t *testing.T) {
+	c, s := newTestClient(t, nil)
+	for i := 0; i < 30; i++ {
+		s.Set(fmt.Sprintf("k%d", i), "v")
+	}
+	start := time.Now()
+	n, err := c.DeleteByPattern(context.Background(), "*", ScanOptions{Count: 5, RateLimit: 10, DryRun: true})
+	if err != nil || n != 30 {
+		t.Fatalf("DeleteByPattern = %d, %v, want 30", n, err)
+	}
+	// 桶容量10，其余20个 key 需要约2s
+	if elapsed := time.Since(start); elapsed < 1500*time.Millisecond {
+		t.Fatalf("scanned 30 keys in %s with RateLimit 10", elapsed)
+	}
+}
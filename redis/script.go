@@ -0,0 +1,269 @@
+package redis
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 内置脚本(锁、限流、布隆过滤器等)
+//
+//go:embed lua/*.lua
+var builtinLua embed.FS
+
+func builtinScript(name string) *redis.Script {
+	src, err := builtinLua.ReadFile("lua/" + name)
+	if err != nil {
+		panic(fmt.Sprintf("redis: builtin script %s not found", name))
+	}
+	return redis.NewScript(string(src))
+}
+
+// ScriptRegistry Lua 脚本注册表，启动时通过 SCRIPT LOAD 预加载，执行时使用 EVALSHA
+// 服务端脚本缓存丢失(重启、SCRIPT FLUSH、故障转移)返回 NOSCRIPT 时自动重新加载
+type ScriptRegistry struct {
+	client *RedisClient
+
+	mu      sync.RWMutex
+	scripts map[string]*redis.Script
+}
+
+// NewScriptRegistry 创建脚本注册表
+func (r *RedisClient) NewScriptRegistry() *ScriptRegistry {
+	return &ScriptRegistry{client: r, scripts: make(map[string]*redis.Script)}
+}
+
+// Register 注册脚本，同名脚本会被覆盖
+func (s *ScriptRegistry) Register(name, src string) *redis.Script {
+	script := redis.NewScript(src)
+	s.mu.Lock()
+	s.scripts[name] = script
+	s.mu.Unlock()
+	return script
+}
+
+// RegisterFS 注册 dir 目录下所有 .lua 文件，脚本名为去掉扩展名的文件名
+// 通常配合 embed.FS 使用: //go:embed lua/*.lua
+func (s *ScriptRegistry) RegisterFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return fmt.Errorf("RegisterFS dir=%s: %w", dir, err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".lua" {
+			continue
+		}
+		src, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("RegisterFS file=%s: %w", entry.Name(), err)
+		}
+		s.Register(strings.TrimSuffix(entry.Name(), ".lua"), string(src))
+	}
+	return nil
+}
+
+// Names 返回已注册的脚本名
+func (s *ScriptRegistry) Names() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	names := make([]string, 0, len(s.scripts))
+	for name := range s.scripts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Load 将所有脚本加载到服务端，集群模式下加载到每个节点
+func (s *ScriptRegistry) Load(ctx context.Context) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for name, script := range s.scripts {
+		if err := script.Load(ctx, s.client).Err(); err != nil {
+			return fmt.Errorf("ScriptRegistry.Load name=%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Run 通过 EVALSHA 执行脚本，返回 NOSCRIPT 时重新加载后重试
+func (s *ScriptRegistry) Run(ctx context.Context, name string, keys []string, args ...interface{}) *redis.Cmd {
+	s.mu.RLock()
+	script, ok := s.scripts[name]
+	s.mu.RUnlock()
+	if !ok {
+		cmd := redis.NewCmd(ctx)
+		cmd.SetErr(fmt.Errorf("script %s not registered", name))
+		return cmd
+	}
+
+	cmd := script.EvalSha(ctx, s.client, keys, args...)
+	if !redis.HasErrorPrefix(cmd.Err(), "NOSCRIPT") {
+		return cmd
+	}
+	if err := script.Load(ctx, s.client).Err(); err != nil {
+		// 加载失败时退化为 EVAL
+		return script.Eval(ctx, s.client, keys, args...)
+	}
+	return script.EvalSha(ctx, s.client, keys, args...)
+}
+
+// RunScript 执行脚本并将结果解码为 T
+func RunScript[T any](ctx context.Context, s *ScriptRegistry, name string, keys []string, args ...interface{}) (T, error) {
+	v, err := ScriptResult[T](s.Run(ctx, name, keys, args...))
+	if err != nil {
+		return v, fmt.Errorf("RunScript name=%s: %w", name, err)
+	}
+	return v, nil
+}
+
+// ScriptResult 将脚本返回值解码为 T
+// Lua 的 true 在 RESP2 下返回 1、false 返回 nil，RESP3(go-redis v9 默认)下均返回 bool，两种协议结果一致
+// 支持 int64、int、float64(脚本以字符串返回)、string、bool、[]byte、[]int64、[]string、[]float64、[]bool、[]interface{} 与 interface{}
+func ScriptResult[T any](cmd *redis.Cmd) (T, error) {
+	var zero T
+	val, err := cmd.Result()
+	if err == redis.Nil {
+		// 脚本返回 nil 或 false(RESP2)
+		val, err = nil, nil
+	}
+	if err != nil {
+		return zero, err
+	}
+	out, err := convertScriptValue(val, any(zero))
+	if err != nil {
+		return zero, err
+	}
+	v, _ := out.(T) // T 为 interface{} 且结果为 nil 时断言失败，返回零值
+	return v, nil
+}
+
+func convertScriptValue(val, target interface{}) (interface{}, error) {
+	switch target.(type) {
+	case int64:
+		return scriptInt(val)
+	case int:
+		n, err := scriptInt(val)
+		return int(n), err
+	case float64:
+		return scriptFloat(val)
+	case string:
+		return scriptString(val)
+	case []byte:
+		s, err := scriptString(val)
+		return []byte(s), err
+	case bool:
+		return scriptBool(val)
+	case []int64:
+		return convertScriptSlice(val, scriptInt)
+	case []string:
+		return convertScriptSlice(val, scriptString)
+	case []float64:
+		return convertScriptSlice(val, scriptFloat)
+	case []bool:
+		return convertScriptSlice(val, scriptBool)
+	case []interface{}:
+		vals, ok := val.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unexpected script result type %T", val)
+		}
+		return vals, nil
+	case nil:
+		// T 为 interface{}
+		return val, nil
+	}
+	return nil, fmt.Errorf("unsupported script result target %T", target)
+}
+
+func convertScriptSlice[E any](val interface{}, conv func(interface{}) (E, error)) ([]E, error) {
+	vals, ok := val.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected script result type %T", val)
+	}
+	out := make([]E, len(vals))
+	for i, v := range vals {
+		e, err := conv(v)
+		if err != nil {
+			return nil, fmt.Errorf("index %d: %w", i, err)
+		}
+		out[i] = e
+	}
+	return out, nil
+}
+
+func scriptInt(val interface{}) (int64, error) {
+	switch v := val.(type) {
+	case int64:
+		return v, nil
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case string:
+		return strconv.ParseInt(v, 10, 64)
+	case nil:
+		return 0, nil
+	}
+	return 0, fmt.Errorf("unexpected script result type %T", val)
+}
+
+func scriptFloat(val interface{}) (float64, error) {
+	switch v := val.(type) {
+	case int64:
+		return float64(v), nil
+	case string:
+		return strconv.ParseFloat(v, 64)
+	case float64:
+		return v, nil
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case nil:
+		return 0, nil
+	}
+	return 0, fmt.Errorf("unexpected script result type %T", val)
+}
+
+func scriptString(val interface{}) (string, error) {
+	switch v := val.(type) {
+	case string:
+		return v, nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case bool:
+		// 与 RESP2 一致: true 为 1，false 为 nil
+		if v {
+			return "1", nil
+		}
+		return "", nil
+	case nil:
+		return "", nil
+	}
+	return "", fmt.Errorf("unexpected script result type %T", val)
+}
+
+// scriptBool RESP2 下 Lua 的 true 转换为 1、false 转换为 nil，RESP3 下直接返回 bool
+func scriptBool(val interface{}) (bool, error) {
+	switch v := val.(type) {
+	case bool:
+		return v, nil
+	case int64:
+		return v != 0, nil
+	case string:
+		return v != "" && v != "0", nil
+	case nil:
+		return false, nil
+	}
+	return false, fmt.Errorf("unexpected script result type %T", val)
+}
@@ -0,0 +1,84 @@
+package redis
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+// RESP3 下 Lua 布尔值返回 bool，miniredis 只返回 RESP2 的 1/nil，因此直接构造输入
+func TestConvertScriptValueBool(t *testing.T) {
+	tests := []struct {
+		val, target, want interface{}
+	}{
+		{true, false, true},
+		{false, false, false},
+		{int64(1), false, true},
+		{nil, false, false},
+		{true, int64(0), int64(1)},
+		{false, int64(0), int64(0)},
+		{true, 0, 1},
+		{true, float64(0), float64(1)},
+		{true, "", "1"},
+		{false, "", ""},
+		{[]interface{}{true, false, int64(1), nil}, []bool(nil), []bool{true, false, true, false}},
+		{[]interface{}{true, false}, []int64(nil), []int64{1, 0}},
+	}
+	for _, tt := range tests {
+		got, err := convertScriptValue(tt.val, tt.target)
+		if err != nil {
+			t.Errorf("convertScriptValue(%#v, %T): %v", tt.val, tt.target, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("convertScriptValue(%#v, %T) = %#v, want %#v", tt.val, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestRunScript(t *testing.T) {
+	c, s := newTestClient(t, nil)
+	ctx := context.Background()
+	reg := c.NewScriptRegistry()
+	err := reg.RegisterFS(fstest.MapFS{
+		"lua/incr.lua":   {Data: []byte(`return redis.call("INCRBY", KEYS[1], ARGV[1])`)},
+		"lua/exists.lua": {Data: []byte(`return redis.call("EXISTS", KEYS[1]) == 1`)},
+		"lua/none.lua":   {Data: []byte(`return nil`)},
+		"lua/readme.md":  {Data: []byte("ignored")},
+	}, "lua")
+	if err != nil {
+		t.Fatalf("RegisterFS: %v", err)
+	}
+	if names := reg.Names(); !reflect.DeepEqual(names, []string{"exists", "incr", "none"}) {
+		t.Fatalf("Names = %v", names)
+	}
+	if err := reg.Load(ctx); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if n, err := RunScript[int](ctx, reg, "incr", []string{"n"}, 2); err != nil || n != 2 {
+		t.Fatalf("RunScript incr = %d, %v, want 2", n, err)
+	}
+	if ok, err := RunScript[bool](ctx, reg, "exists", []string{"n"}); err != nil || !ok {
+		t.Fatalf("RunScript exists = %v, %v, want true", ok, err)
+	}
+	if ok, err := RunScript[bool](ctx, reg, "exists", []string{"missing"}); err != nil || ok {
+		t.Fatalf("RunScript exists missing = %v, %v, want false", ok, err)
+	}
+	if v, err := RunScript[interface{}](ctx, reg, "none", nil); err != nil || v != nil {
+		t.Fatalf("RunScript none = %v, %v, want nil", v, err)
+	}
+
+	// 脚本缓存丢失后自动重新加载
+	s.FlushAll()
+	if err := c.ScriptFlush(ctx).Err(); err != nil {
+		t.Fatalf("SCRIPT FLUSH: %v", err)
+	}
+	if n, err := RunScript[int64](ctx, reg, "incr", []string{"n"}, 3); err != nil || n != 3 {
+		t.Fatalf("RunScript after flush = %d, %v, want 3", n, err)
+	}
+	if _, err := RunScript[int](ctx, reg, "unknown", nil); err == nil {
+		t.Fatal("RunScript unknown succeeded, want error")
+	}
+}
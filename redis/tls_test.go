@@ -0,0 +1,216 @@
+package redis
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// testCert 生成自签名证书，证书与私钥写入临时目录，证书同时作为 CA
+func testCert(t *testing.T) (certFile, keyFile string, cert tls.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "redis.test"},
+		DNSNames:              []string{"redis.test"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, keyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cert, err = tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile, cert
+}
+
+func TestBuildTLSConfig(t *testing.T) {
+	certFile, keyFile, _ := testCert(t)
+	badFile := filepath.Join(t.TempDir(), "bad.pem")
+	if err := os.WriteFile(badFile, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg, err := buildTLSConfig(nil); cfg != nil || err != nil {
+		t.Fatalf("buildTLSConfig(nil) = %v, %v", cfg, err)
+	}
+	if cfg, err := buildTLSConfig(&TLSConfig{CAFile: certFile}); cfg != nil || err != nil {
+		t.Fatalf("buildTLSConfig(disabled) = %v, %v", cfg, err)
+	}
+
+	cfg, err := buildTLSConfig(&TLSConfig{Enabled: true, CAFile: certFile, CertFile: certFile, KeyFile: keyFile,
+		ServerName: "redis.test"})
+	if err != nil {
+		t.Fatalf("buildTLSConfig: %v", err)
+	}
+	if cfg.MinVersion != tls.VersionTLS12 || cfg.ServerName != "redis.test" || cfg.RootCAs == nil ||
+		len(cfg.Certificates) != 1 || cfg.InsecureSkipVerify {
+		t.Fatalf("tls.Config = %+v", cfg)
+	}
+
+	for name, c := range map[string]*TLSConfig{
+		"missing ca":  {Enabled: true, CAFile: filepath.Join(t.TempDir(), "none.pem")},
+		"invalid ca":  {Enabled: true, CAFile: badFile},
+		"key only":    {Enabled: true, KeyFile: keyFile},
+		"invalid key": {Enabled: true, CertFile: certFile, KeyFile: badFile},
+	} {
+		if _, err := buildTLSConfig(c); err == nil {
+			t.Errorf("%s: buildTLSConfig succeeded, want error", name)
+		}
+	}
+}
+
+func TestClientTLS(t *testing.T) {
+	certFile, keyFile, cert := testCert(t)
+	s, err := miniredis.RunTLS(&tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatalf("RunTLS: %v", err)
+	}
+	t.Cleanup(s.Close)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		tls     *TLSConfig
+		wantErr bool
+	}{
+		{"ca", &TLSConfig{Enabled: true, CAFile: certFile, ServerName: "redis.test"}, false},
+		{"client certificate", &TLSConfig{Enabled: true, CAFile: certFile, CertFile: certFile, KeyFile: keyFile}, false},
+		{"insecure", &TLSConfig{Enabled: true, InsecureSkipVerify: true}, false},
+		{"unknown authority", &TLSConfig{Enabled: true}, true},
+		{"server name mismatch", &TLSConfig{Enabled: true, CAFile: certFile, ServerName: "other.test"}, true},
+		{"plaintext", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// NewClient 建连时会 PING，握手失败直接返回错误
+			c, err := NewClient(&Config{Address: s.Addr(), TLS: tt.tls, RetryTimes: -1, DialTimeout: 1, Timeout: 1})
+			if err == nil {
+				defer c.Close()
+				err = c.Ping(ctx).Err()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClientACL(t *testing.T) {
+	s := miniredis.RunT(t)
+	s.RequireUserAuth("svc", "secret")
+	ctx := context.Background()
+
+	for _, tt := range []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{"username", Config{Username: "svc", Password: "secret"}, false},
+		{"wrong password", Config{Username: "svc", Password: "wrong"}, true},
+		{"default user", Config{Password: "secret"}, true},
+		// CredentialsProvider 优先于 Username/Password
+		{"provider", Config{Username: "svc", Password: "wrong",
+			CredentialsProvider: func() (string, string) { return "svc", "secret" }}, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			cfg.Address = s.Addr()
+			cfg.RetryTimes = -1
+			c, err := NewClient(&cfg)
+			if err == nil {
+				defer c.Close()
+				err = c.Ping(ctx).Err()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// 密码轮换后新建的连接使用 CredentialsProvider 返回的最新凭据
+func TestClientCredentialsRotation(t *testing.T) {
+	s := miniredis.RunT(t)
+	s.RequireUserAuth("svc", "v1")
+	var password atomic.Value
+	password.Store("v1")
+	var calls atomic.Int32
+	c, err := NewClient(&Config{Address: s.Addr(), RetryTimes: -1,
+		CredentialsProvider: func() (string, string) {
+			calls.Add(1)
+			return "svc", password.Load().(string)
+		}})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+	if err := c.Ping(ctx).Err(); err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+
+	password.Store("v2")
+	before := calls.Load()
+	// 重启服务端断开已有连接，迫使客户端重新建连认证
+	s.Close()
+	if err := s.Restart(); err != nil {
+		t.Fatalf("Restart: %v", err)
+	}
+	s.RequireUserAuth("svc", "v2")
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if err = c.Ping(ctx).Err(); err == nil || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("Ping after rotation: %v", err)
+	}
+	if calls.Load() <= before {
+		t.Fatalf("CredentialsProvider not called on reconnect")
+	}
+}
@@ -0,0 +1,81 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+var (
+	// 累加分数后保留分数最高的 ARGV[3] 个成员
+	topKIncrScript = builtinScript("topk_incr.lua")
+
+	// 所有成员分数乘以 ARGV[1]，低于 ARGV[2] 的成员被移除，返回移除数量
+	topKDecayScript = builtinScript("topk_decay.lua")
+)
+
+// TopKItem 排行榜成员
+type TopKItem struct {
+	Member string
+	Score  float64
+}
+
+// TopK 基于有序集合的排行榜，保留 capacity 个候选成员，定期调用 Decay 让旧热度衰减
+type TopK struct {
+	client   *RedisClient
+	key      string
+	k        int
+	capacity int
+}
+
+// NewTopK 创建排行榜，保留的候选成员数为 max(4k, 100)，以便新成员有机会进入前 k
+func (r *RedisClient) NewTopK(name string, k int) *TopK {
+	capacity := 4 * k
+	if capacity < 100 {
+		capacity = 100
+	}
+	return &TopK{client: r, key: "topk:{" + name + "}", k: k, capacity: capacity}
+}
+
+// Incr 增加成员分数，返回累加后的分数
+func (t *TopK) Incr(ctx context.Context, member string, by float64) (float64, error) {
+	v, err := topKIncrScript.Run(WithNonIdempotent(ctx), t.client, []string{t.key}, member, by, t.capacity).Text()
+	if err != nil {
+		return 0, fmt.Errorf("TopK.Incr key=%s: %w", t.key, err)
+	}
+	return strconv.ParseFloat(v, 64)
+}
+
+// List 返回分数最高的 k 个成员，按分数降序
+func (t *TopK) List(ctx context.Context) ([]TopKItem, error) {
+	zs, err := t.client.ZRevRangeWithScores(ctx, t.key, 0, int64(t.k-1)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("TopK.List key=%s: %w", t.key, err)
+	}
+	items := make([]TopKItem, len(zs))
+	for i, z := range zs {
+		member, _ := z.Member.(string)
+		items[i] = TopKItem{Member: member, Score: z.Score}
+	}
+	return items, nil
+}
+
+// Decay 所有成员分数乘以 factor(0~1)，分数低于 min 的成员被移除，返回移除数量
+func (t *TopK) Decay(ctx context.Context, factor, min float64) (int64, error) {
+	if factor <= 0 || factor > 1 {
+		return 0, fmt.Errorf("TopK.Decay key=%s: factor must be in (0, 1]", t.key)
+	}
+	n, err := topKDecayScript.Run(ctx, t.client, []string{t.key}, factor, min).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("TopK.Decay key=%s: %w", t.key, err)
+	}
+	return n, nil
+}
+
+// Reset 清空排行榜
+func (t *TopK) Reset(ctx context.Context) error {
+	if err := t.client.Del(ctx, t.key).Err(); err != nil {
+		return fmt.Errorf("TopK.Reset key=%s: %w", t.key, err)
+	}
+	return nil
+}
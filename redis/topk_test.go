@@ -0,0 +1,89 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestTopK(t *testing.T) {
+	c, _ := newTestClient(t, nil)
+	ctx := context.Background()
+	top := c.NewTopK("hot", 2)
+	if top.capacity != 100 || top.key != "topk:{hot}" {
+		t.Fatalf("NewTopK capacity = %d, key = %s", top.capacity, top.key)
+	}
+	if c.NewTopK("wide", 50).capacity != 200 {
+		t.Fatal("capacity should be 4k for large k")
+	}
+
+	for _, tt := range []struct {
+		member string
+		by     float64
+		want   float64
+	}{
+		{"a", 1, 1},
+		{"b", 3, 3},
+		{"a", 1.5, 2.5},
+		{"c", 0.5, 0.5},
+	} {
+		if v, err := top.Incr(ctx, tt.member, tt.by); err != nil || v != tt.want {
+			t.Fatalf("Incr(%s, %g) = %g, %v, want %g", tt.member, tt.by, v, err, tt.want)
+		}
+	}
+	items, err := top.List(ctx)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	want := []TopKItem{{"b", 3}, {"a", 2.5}}
+	if len(items) != len(want) || items[0] != want[0] || items[1] != want[1] {
+		t.Fatalf("List = %v, want %v", items, want)
+	}
+
+	// 分数减半后低于1的成员被移除
+	if n, err := top.Decay(ctx, 0.5, 1); err != nil || n != 1 {
+		t.Fatalf("Decay = %d, %v, want 1 removed", n, err)
+	}
+	items, err = top.List(ctx)
+	if err != nil || len(items) != 2 || items[0] != (TopKItem{"b", 1.5}) || items[1] != (TopKItem{"a", 1.25}) {
+		t.Fatalf("List after Decay = %v, %v", items, err)
+	}
+	if card := c.ZCard(ctx, top.key).Val(); card != 2 {
+		t.Fatalf("ZCARD after Decay = %d, want 2", card)
+	}
+
+	for _, factor := range []float64{0, -1, 1.5} {
+		if _, err := top.Decay(ctx, factor, 0); err == nil {
+			t.Errorf("Decay(%g) succeeded, want error", factor)
+		}
+	}
+
+	if err := top.Reset(ctx); err != nil {
+		t.Fatalf("Reset: %v", err)
+	}
+	if items, err := top.List(ctx); err != nil || len(items) != 0 {
+		t.Fatalf("List after Reset = %v, %v", items, err)
+	}
+}
+
+// 超过 capacity 时淘汰分数最低的候选成员
+func TestTopKCapacity(t *testing.T) {
+	c, _ := newTestClient(t, nil)
+	ctx := context.Background()
+	top := c.NewTopK("trim", 1)
+	for i := 1; i <= top.capacity+20; i++ {
+		if _, err := top.Incr(ctx, fmt.Sprintf("m%d", i), float64(i)); err != nil {
+			t.Fatalf("Incr: %v", err)
+		}
+	}
+	if card := c.ZCard(ctx, top.key).Val(); card != int64(top.capacity) {
+		t.Fatalf("ZCARD = %d, want %d", card, top.capacity)
+	}
+	if _, err := c.ZScore(ctx, top.key, "m20").Result(); err == nil {
+		t.Fatal("lowest member m20 kept, want evicted")
+	}
+	items, err := top.List(ctx)
+	if err != nil || len(items) != 1 || items[0].Member != fmt.Sprintf("m%d", top.capacity+20) {
+		t.Fatalf("List = %v, %v", items, err)
+	}
+}
@@ -1,5 +1,11 @@
 package redis
 
+import (
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
 const (
 	// CacheFormatRaw raw
 	CacheFormatRaw = 0
@@ -9,13 +15,52 @@ const (
 	CacheFormatJSON = 10
 	// CacheFormatJSONGzip json gzip
 	CacheFormatJSONGzip = 11
+	// CacheFormatJSONZstd json zstd
+	CacheFormatJSONZstd = 12
+	// CacheFormatJSONSnappy json snappy
+	CacheFormatJSONSnappy = 13
+	// CacheFormatMsgpack msgpack
+	CacheFormatMsgpack = 20
+	// CacheFormatMsgpackGzip msgpack gzip
+	CacheFormatMsgpackGzip = 21
+	// CacheFormatMsgpackZstd msgpack zstd
+	CacheFormatMsgpackZstd = 22
+	// CacheFormatMsgpackSnappy msgpack snappy
+	CacheFormatMsgpackSnappy = 23
+	// CacheFormatProtobuf protobuf
+	CacheFormatProtobuf = 30
+	// CacheFormatProtobufGzip protobuf gzip
+	CacheFormatProtobufGzip = 31
+	// CacheFormatProtobufZstd protobuf zstd
+	CacheFormatProtobufZstd = 32
+	// CacheFormatProtobufSnappy protobuf snappy
+	CacheFormatProtobufSnappy = 33
+)
+
+const (
+	// ModeSingle 单节点
+	ModeSingle = "single"
+	// ModeSentinel 哨兵
+	ModeSentinel = "sentinel"
+	// ModeCluster 集群
+	ModeCluster = "cluster"
 )
 
 type Config struct {
+	// 部署模式: single(默认)、sentinel、cluster
+	Mode string `yaml:"mode" json:"mode"`
 	// redis服务器地址，ip:port格式 默认为 :6379
 	Address string `yaml:"address" json:"address"`
+	// ACL 用户名，默认为空(使用 default 用户)
+	Username string `yaml:"username" json:"username"`
 	// 默认为空，不进行认证
 	Password string `yaml:"password" json:"password"`
+	// 动态获取用户名与密码(如定期轮换的密码)，设置后优先于 Username/Password，只能通过代码设置
+	CredentialsProvider func() (username string, password string) `yaml:"-" json:"-"`
+	// TLS 连接配置，为空时不开启
+	TLS *TLSConfig `yaml:"tls" json:"tls"`
+	// key 前缀，对所有命令(含 pipeline、脚本与 SCAN)透明生效，用于多服务或多环境共用同一 Redis
+	KeyPrefix string `yaml:"key_prefix" json:"key_prefix"`
 	// redis DB 数据库，默认为0
 	DB int `yaml:"db" json:"db"`
 	//连接池最大连接数量,默认为 10 * runtime.GOMAXPROCS
@@ -28,12 +73,43 @@ type Config struct {
 	DialTimeout int `yaml:"dial_timeout"`
 	// Timeout 读写超时时间, 默认3秒， -1表示取消读超时
 	Timeout int `yaml:"timeout" json:"timeout"`
-	// 重试次数,默认为0
+	// 重试次数,默认为3(与 go-redis 一致)，-1表示不重试，对所有命令生效，仅对网络异常、主从切换等临时性错误重试
 	RetryTimes int `yaml:"retry_times" json:"retry_times"`
-	// 压缩阈值配置,默认2048
+	// 首次重试前的退避时间，之后指数增长，默认8ms
+	MinRetryBackoff time.Duration `yaml:"min_retry_backoff" json:"min_retry_backoff"`
+	// 单次重试退避时间上限，默认512ms
+	MaxRetryBackoff time.Duration `yaml:"max_retry_backoff" json:"max_retry_backoff"`
+	// 压缩阈值配置,默认2048，对所有压缩器生效
 	GzipMinSize int `yaml:"gzip_min_size" json:"gzip_min_size"`
+	// 序列化方式: raw、json(默认)、msgpack、protobuf
+	Codec string `yaml:"codec" json:"codec"`
+	// 压缩方式: none、gzip(默认)、zstd、snappy
+	Compressor string `yaml:"compressor" json:"compressor"`
+
+	// sentinel 模式下的 master 名称
+	MasterName string `yaml:"master_name" json:"master_name"`
+	// sentinel 节点地址列表
+	SentinelAddrs []string `yaml:"sentinel_addrs" json:"sentinel_addrs"`
+	// sentinel 节点 ACL 用户名，默认为空
+	SentinelUsername string `yaml:"sentinel_username" json:"sentinel_username"`
+	// sentinel 节点认证密码，默认为空
+	SentinelPassword string `yaml:"sentinel_password" json:"sentinel_password"`
+	// cluster 节点地址列表
+	ClusterAddrs []string `yaml:"cluster_addrs" json:"cluster_addrs"`
+	// 只读命令路由到从节点，cluster 与 sentinel 模式有效
+	ReadOnly bool `yaml:"read_only" json:"read_only"`
+	// 只读命令路由到延迟最低的节点，开启后隐含 ReadOnly
+	RouteByLatency bool `yaml:"route_by_latency" json:"route_by_latency"`
+	// 只读命令随机路由到任一节点，开启后隐含 ReadOnly
+	RouteRandomly bool `yaml:"route_randomly" json:"route_randomly"`
+
+	// 命令延迟、命中率与连接池状态统计，为空时不开启
+	Stats *StatsConfig `yaml:"stats" json:"stats"`
+	// 设置后为每条命令生成 OpenTelemetry span，只能通过代码设置
+	TracerProvider trace.TracerProvider `yaml:"-" json:"-"`
 }
 
+// modelCacheItem 历史 JSON 缓存格式，仅用于兼容读取
 type modelCacheItem struct {
 	Flag uint32
 	Data []byte